@@ -0,0 +1,246 @@
+package raph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Automaton is a compiled label sequence. Labels are separated by spaces and can be combined with parentheses, alternation (|) and quantifiers (*, + and ?), e.g. "truck* flight (truck | rail)*".
+// Each state, except the initial state 0, is entered by crossing an edge with the label of the state.
+type Automaton struct {
+	labels    []string
+	follow    [][]int
+	accepting map[int]bool
+}
+
+// fragment is a compiled subexpression: whether or not it matches the empty sequence, and its first and last states.
+type fragment struct {
+	nullable bool
+	first    []int
+	last     []int
+}
+
+// CompileSequence compiles the label sequence expression into an automaton.
+func CompileSequence(expr string) (*Automaton, error) {
+	p := &sequenceParser{tokens: tokenizeSequence(expr)}
+	p.automaton = &Automaton{[]string{""}, [][]int{{}}, map[int]bool{}}
+
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty label sequence")
+	}
+
+	f, err := p.parseAlternation()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in label sequence %q", p.tokens[p.pos], expr)
+	}
+
+	a := p.automaton
+	a.link([]int{0}, f.first)
+	for _, state := range f.last {
+		a.accepting[state] = true
+	}
+	if f.nullable {
+		a.accepting[0] = true
+	}
+	for state := range a.follow {
+		a.follow[state] = uniqueInts(a.follow[state])
+	}
+
+	return a, nil
+}
+
+// Next returns the states reachable from the specified state.
+func (a Automaton) Next(state int) []int {
+	return a.follow[state]
+}
+
+// Label returns the label of the edges entering the specified state.
+func (a Automaton) Label(state int) string {
+	return a.labels[state]
+}
+
+// Accepts returns whether or not the specified state ends a valid sequence.
+func (a Automaton) Accepts(state int) bool {
+	return a.accepting[state]
+}
+
+// GetLabels returns the sorted labels used by the automaton.
+func (a Automaton) GetLabels() []string {
+	set := map[string]bool{}
+	for _, label := range a.labels[1:] {
+		set[label] = true
+	}
+	labels := ToSlice(set)
+	sort.Strings(labels)
+	return labels
+}
+
+// link adds transitions from all states of froms to all states of tos.
+func (a *Automaton) link(froms, tos []int) {
+	for _, from := range froms {
+		a.follow[from] = append(a.follow[from], tos...)
+	}
+}
+
+// sequenceParser parses label sequences by recursive descent.
+type sequenceParser struct {
+	tokens    []string
+	pos       int
+	automaton *Automaton
+}
+
+// parseAlternation parses terms separated by |.
+func (p *sequenceParser) parseAlternation() (fragment, error) {
+	f, err := p.parseConcatenation()
+	if err != nil {
+		return f, err
+	}
+
+	for p.pos < len(p.tokens) && p.tokens[p.pos] == "|" {
+		p.pos++
+		g, err := p.parseConcatenation()
+		if err != nil {
+			return f, err
+		}
+		f = fragment{f.nullable || g.nullable, union(f.first, g.first), union(f.last, g.last)}
+	}
+
+	return f, nil
+}
+
+// parseConcatenation parses a sequence of quantified atoms.
+func (p *sequenceParser) parseConcatenation() (fragment, error) {
+	f := fragment{true, []int{}, []int{}}
+	parsed := false
+
+	for p.pos < len(p.tokens) && p.tokens[p.pos] != "|" && p.tokens[p.pos] != ")" {
+		g, err := p.parseQuantified()
+		if err != nil {
+			return f, err
+		}
+
+		// last states of f are followed by first states of g
+		p.automaton.link(f.last, g.first)
+
+		first := f.first
+		if f.nullable {
+			first = union(f.first, g.first)
+		}
+		last := g.last
+		if g.nullable {
+			last = union(g.last, f.last)
+		}
+		f = fragment{f.nullable && g.nullable, first, last}
+		parsed = true
+	}
+
+	if !parsed {
+		return f, fmt.Errorf("missing label in label sequence")
+	}
+	return f, nil
+}
+
+// parseQuantified parses an atom followed by quantifiers.
+func (p *sequenceParser) parseQuantified() (fragment, error) {
+	f, err := p.parseAtom()
+	if err != nil {
+		return f, err
+	}
+
+	for p.pos < len(p.tokens) {
+		switch p.tokens[p.pos] {
+		case "*":
+			p.automaton.link(f.last, f.first)
+			f.nullable = true
+		case "+":
+			p.automaton.link(f.last, f.first)
+		case "?":
+			f.nullable = true
+		default:
+			return f, nil
+		}
+		p.pos++
+	}
+
+	return f, nil
+}
+
+// parseAtom parses a label or a parenthesized expression.
+func (p *sequenceParser) parseAtom() (fragment, error) {
+	token := p.tokens[p.pos]
+	p.pos++
+
+	switch token {
+	case "(":
+		f, err := p.parseAlternation()
+		if err != nil {
+			return f, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return f, fmt.Errorf("missing ) in label sequence")
+		}
+		p.pos++
+		return f, nil
+	case "*", "+", "?":
+		return fragment{}, fmt.Errorf("unexpected %q in label sequence", token)
+	}
+
+	// each label occurrence is a new state
+	a := p.automaton
+	state := len(a.labels)
+	a.labels = append(a.labels, token)
+	a.follow = append(a.follow, []int{})
+	return fragment{false, []int{state}, []int{state}}, nil
+}
+
+// tokenizeSequence splits a label sequence into labels and operators.
+func tokenizeSequence(expr string) []string {
+	tokens := []string{}
+	label := strings.Builder{}
+
+	flush := func() {
+		if label.Len() > 0 {
+			tokens = append(tokens, label.String())
+			label.Reset()
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case strings.ContainsRune("()|*+?", r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			label.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// union returns a new slice containing the values of a and b.
+func union(a, b []int) []int {
+	return append(append([]int{}, a...), b...)
+}
+
+// uniqueInts returns the sorted distinct values of s.
+func uniqueInts(s []int) []int {
+	set := map[int]bool{}
+	unique := []int{}
+	for _, v := range s {
+		if !set[v] {
+			set[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Ints(unique)
+	return unique
+}
@@ -0,0 +1,80 @@
+package raph
+
+import (
+	"strings"
+	"testing"
+)
+
+// matches returns whether or not the automaton accepts the space separated labels.
+func matches(a *Automaton, labels string) bool {
+	states := []int{0}
+	for _, label := range strings.Fields(labels) {
+		next := []int{}
+		for _, state := range states {
+			for _, n := range a.Next(state) {
+				if a.Label(n) == label {
+					next = append(next, n)
+				}
+			}
+		}
+		states = next
+	}
+	for _, state := range states {
+		if a.Accepts(state) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCompileSequence(t *testing.T) {
+	for _, c := range []struct {
+		expr               string
+		accepted, rejected []string
+	}{
+		{"truck* flight truck*", []string{"flight", "truck flight", "truck truck flight truck"}, []string{"", "truck", "flight flight", "truck rail flight"}},
+		{"truck (flight (rail | bus))+", []string{"truck flight rail", "truck flight bus flight rail"}, []string{"truck", "truck flight", "truck rail"}},
+		{"truck? flight+", []string{"flight", "truck flight flight"}, []string{"truck", "truck truck flight"}},
+		{"(truck | rail*) flight", []string{"flight", "truck flight", "rail rail flight"}, []string{"truck rail flight"}},
+		{"(truck | rail?)*", []string{"", "truck", "rail truck rail"}, []string{"flight"}},
+	} {
+		a, err := CompileSequence(c.expr)
+		if err != nil {
+			t.Fatalf("expected %q to compile, got %v", c.expr, err)
+		}
+		for _, labels := range c.accepted {
+			if !matches(a, labels) {
+				t.Errorf("expected %q to accept %q", c.expr, labels)
+			}
+		}
+		for _, labels := range c.rejected {
+			if matches(a, labels) {
+				t.Errorf("expected %q to reject %q", c.expr, labels)
+			}
+		}
+	}
+
+	for _, expr := range []string{"", "()", "a |", "| a", "*a", "a (b", "a b)", "(a))"} {
+		if _, err := CompileSequence(expr); err == nil {
+			t.Errorf("expected %q not to compile", expr)
+		}
+	}
+}
+
+func TestSequenceQuery(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"AB", "A", "B", map[string]float64{"price": 1}},
+		testEdge{"BC", "B", "C", map[string]float64{"price": 1}},
+		testEdge{"AC", "A", "C", map[string]float64{"price": 5}},
+	)
+
+	query := `{"from": "A", "to": "C", "constraint": {"label": "road"}, "minimize": ["price"], "sequence": "`
+	for _, c := range []struct {
+		sequence string
+		cost     float64
+	}{{"road", 5}, {"road road", 2}, {"road+", 2}, {"road road road", -1}} {
+		if result := NewQuery(query + c.sequence + `"}`).Run(*g); result["cost"] != c.cost {
+			t.Fatalf("expected the path matching %q to cost %v, got %v", c.sequence, c.cost, result)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package raph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CostCapacity is the cost key holding the remaining capacity of an edge. Edges without capacity are unlimited.
+const CostCapacity = "capacity"
+
+var (
+	// ErrInsufficientCapacity is returned by Reserve when an edge of the path has not enough remaining capacity.
+	ErrInsufficientCapacity = errors.New("insufficient capacity")
+	// ErrExcessRelease is returned by Release when an edge of the path has less reserved capacity than released.
+	ErrExcessRelease = errors.New("release exceeds reservation")
+)
+
+// Reserve decrements the capacity of the edges of the path by amount. Path is a slice of ids, as returned by queries: vertices and edges without capacity are ignored. Either all edges are updated or none, and an error is returned if one of them has not enough remaining capacity.
+func (g *Graph) Reserve(path []string, amount float64) error {
+	if amount < 0 {
+		return fmt.Errorf("amount should be positive")
+	}
+	return g.reserve(path, amount)
+}
+
+// Release increments the capacity of the edges of the path by amount, to cancel a reservation. Either all edges are updated or none, and an error is returned if one of them has less reserved capacity than amount.
+func (g *Graph) Release(path []string, amount float64) error {
+	if amount < 0 {
+		return fmt.Errorf("amount should be positive")
+	}
+	return g.reserve(path, -amount)
+}
+
+// reserve decrements the capacity of the edges of the path by amount, and increments their Reserved capacity as much, within a transaction. Edges crossed several times are decremented several times.
+func (g *Graph) reserve(path []string, amount float64) error {
+	ids := []string{}
+	reserved := map[string]float64{}
+	for _, id := range path {
+		if g.hasVertex(id) {
+			continue
+		}
+		edge, ok := g.Edges[id]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownID, id)
+		}
+		if _, ok := edge.Costs[CostCapacity]; !ok {
+			continue
+		}
+		if _, ok := reserved[id]; !ok {
+			ids = append(ids, id)
+		}
+		reserved[id] += amount
+	}
+
+	t := g.Begin()
+	for _, id := range ids {
+		capacity := g.Edges[id].Costs[CostCapacity] - reserved[id]
+		if capacity < 0 {
+			return fmt.Errorf("edge %s: %w", id, ErrInsufficientCapacity)
+		}
+		held := g.Edges[id].Reserved + reserved[id]
+		if held < -epsilon {
+			return fmt.Errorf("edge %s: %w", id, ErrExcessRelease)
+		}
+		t.SetCost(id, CostCapacity, capacity)
+		t.setReserved(id, math.Max(0, held))
+	}
+	return t.Commit()
+}
+
+// withDemand returns the query with a constraint filtering out the edges whose remaining capacity is lower than its demand, if any.
+func (q Query) withDemand() Query {
+	if q.Demand <= 0 {
+		return q
+	}
+
+	constraint := q.Constraint.Copy()
+	if threshold, ok := constraint.Edge.Costs[CostCapacity]; ok {
+		constraint.Edge.SetCost(CostCapacity, math.Max(threshold, q.Demand))
+	} else {
+		constraint.Edge.SetCost(CostCapacity, q.Demand)
+	}
+	q.Constraint = constraint
+	return q
+}
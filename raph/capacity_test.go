@@ -0,0 +1,34 @@
+package raph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReleaseExceedingReservation(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"AB", "A", "B", map[string]float64{CostCapacity: 10}},
+		testEdge{"BC", "B", "C", map[string]float64{CostCapacity: 10, "reserved": 1}},
+	)
+	path := []string{"A", "AB", "B", "BC", "C"}
+
+	if err := g.Reserve(path, 4); err != nil {
+		t.Fatalf("expected the reservation to succeed, got %v", err)
+	}
+	if err := g.Release([]string{"A", "AB", "B"}, 4); err != nil {
+		t.Fatalf("expected the release to succeed, got %v", err)
+	}
+
+	// BC still holds 4, but AB holds nothing
+	if err := g.Release(path, 1); !errors.Is(err, ErrExcessRelease) {
+		t.Fatalf("expected ErrExcessRelease, got %v", err)
+	}
+	if g.Edges["AB"].Costs[CostCapacity] != 10 || g.Edges["BC"].Costs[CostCapacity] != 6 {
+		t.Fatalf("expected capacities 10 and 6, got %v and %v", g.Edges["AB"].Costs, g.Edges["BC"].Costs)
+	}
+
+	// costs of the edges are left to the user
+	if err := g.Release([]string{"BC"}, 4); err != nil || g.Edges["BC"].Costs[CostCapacity] != 10 || g.Edges["BC"].Reserved != 0 || g.Edges["BC"].Costs["reserved"] != 1 {
+		t.Fatalf("expected BC to be fully released, got %v, %v", err, g.Edges["BC"].Costs)
+	}
+}
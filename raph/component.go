@@ -37,6 +37,20 @@ func (c Component) Copy() *Component {
 	return component
 }
 
+// Merge adds the props and costs of the specified component to the component. Props are merged without duplicates and costs are overridden.
+func (c *Component) Merge(component Component) {
+	for prop, values := range component.Props {
+		for _, value := range values {
+			if !Contains(c.Props[prop], value) {
+				c.AddProp(prop, value)
+			}
+		}
+	}
+	for cost, value := range component.Costs {
+		c.SetCost(cost, value)
+	}
+}
+
 // Satisfies returns whether or not the component satisfies the props and costs (threshold) of the specified component.
 func (c Component) Satisfies(component Component) bool {
 	// check props
@@ -1,22 +1,153 @@
 package raph
 
+import (
+	"sort"
+)
+
+// Direction defines which way edges are traversed.
+type Direction string
+
+const (
+	// DirectionOut follows edges from their origins to their destinations. It is the default direction.
+	DirectionOut Direction = "out"
+	// DirectionIn follows edges backwards, from their destinations to their origins.
+	DirectionIn Direction = "in"
+	// DirectionBoth follows edges both ways.
+	DirectionBoth Direction = "both"
+)
+
+// Inverse returns the opposite direction.
+func (d Direction) Inverse() Direction {
+	switch d {
+	case DirectionIn:
+		return DirectionOut
+	case DirectionBoth:
+		return DirectionBoth
+	default:
+		return DirectionIn
+	}
+}
+
+// known returns whether or not the direction exists. The empty direction is the default one.
+func (d Direction) known() bool {
+	return d == "" || d == DirectionOut || d == DirectionIn || d == DirectionBoth
+}
+
+// VertexConstraint is a constraint over vertices. Besides props and costs, vertices can be filtered on their label: if Labels is not empty, it lists the allowed labels; ExcludedLabels lists forbidden labels.
+type VertexConstraint struct {
+	Component
+	Labels         []string `json:"labels"`
+	ExcludedLabels []string `json:"~labels"`
+}
+
+// NewVertexConstraint returns an empty vertex constraint.
+func NewVertexConstraint() *VertexConstraint {
+	return &VertexConstraint{*NewComponent(), []string{}, []string{}}
+}
+
+// Copy returns a copy of the vertex constraint.
+func (c VertexConstraint) Copy() *VertexConstraint {
+	labels := append([]string{}, c.Labels...)
+	excludedLabels := append([]string{}, c.ExcludedLabels...)
+	return &VertexConstraint{*c.Component.Copy(), labels, excludedLabels}
+}
+
+// Accepts returns whether or not the vertex satisfies the label, props and costs constraints.
+func (c VertexConstraint) Accepts(v Vertex) bool {
+	if len(c.Labels) > 0 && !Contains(c.Labels, v.Label) {
+		return false
+	}
+	if Contains(c.ExcludedLabels, v.Label) {
+		return false
+	}
+	return v.Satisfies(c.Component)
+}
+
 // Constraint is an instance used to filter out nodes. It inherits from Vertex structure because it behaves more or less like a Vertex against which we will compare vertices and edges. Its ID is not important.
+// Edges are traversed if their label is Label or one of the keys of Labels. Labels values are additional constraints on edges with that label.
 type Constraint struct {
-	Vertex *Component `json:"vertex"`
-	Edge   *Component `json:"edge"`
-	Label  string     `json:"label"`
+	Vertex    *VertexConstraint     `json:"vertex"`
+	Edge      *Component            `json:"edge"`
+	Label     string                `json:"label"`
+	Labels    map[string]*Component `json:"labels"`
+	Direction Direction             `json:"direction"`
 }
 
 // NewConstraint returns a constraint with specified label.
 func NewConstraint(label string) *Constraint {
-	vertex := NewComponent()
+	vertex := NewVertexConstraint()
 	edge := NewComponent()
-	return &Constraint{vertex, edge, label}
+	return &Constraint{vertex, edge, label, map[string]*Component{}, DirectionOut}
 }
 
 // Copy returns a copy of the constraint.
 func (c Constraint) Copy() *Constraint {
 	vertex := c.Vertex.Copy()
 	edge := c.Edge.Copy()
-	return &Constraint{vertex, edge, c.Label}
+	labels := map[string]*Component{}
+	for label, component := range c.Labels {
+		if component != nil {
+			component = component.Copy()
+		}
+		labels[label] = component
+	}
+	return &Constraint{vertex, edge, c.Label, labels, c.Direction}
+}
+
+// AddLabel adds a label to traverse. Edges with that label should also satisfy the specified component, which can be nil.
+func (c *Constraint) AddLabel(label string, edge *Component) {
+	if c.Labels == nil {
+		c.Labels = map[string]*Component{}
+	}
+	c.Labels[label] = edge
+}
+
+// GetLabels returns the sorted labels to traverse.
+func (c Constraint) GetLabels() []string {
+	labels := []string{}
+	if _, ok := c.Labels[c.Label]; !ok && (c.Label != "" || len(c.Labels) == 0) {
+		labels = append(labels, c.Label)
+	}
+	for label := range c.Labels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// allowsEdge returns whether or not the edge satisfies the label and edge constraints. A constraint without any label allows all edges.
+func (c Constraint) allowsEdge(e *Edge) bool {
+	if !e.Satisfies(*c.Edge) {
+		return false
+	}
+	if c.Label == "" && len(c.Labels) == 0 {
+		return true
+	}
+	if component, ok := c.Labels[e.Label]; ok {
+		return component == nil || e.Satisfies(*component)
+	}
+	return e.Label == c.Label
+}
+
+// traversal is a connection label to follow, with the additional constraint its edges should satisfy.
+type traversal struct {
+	label string
+	edge  *Component
+}
+
+// traversals returns the connections to follow for each label, depending on the direction. Inverse connections are indexed with a "~" prefix.
+func (c Constraint) traversals() []traversal {
+	traversals := []traversal{}
+	for _, label := range c.GetLabels() {
+		edge := c.Labels[label]
+		switch c.Direction {
+		case DirectionIn:
+			traversals = append(traversals, traversal{"~" + label, edge})
+		case DirectionBoth:
+			traversals = append(traversals, traversal{label, edge}, traversal{"~" + label, edge})
+		default:
+			traversals = append(traversals, traversal{label, edge})
+		}
+	}
+	return traversals
 }
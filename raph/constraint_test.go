@@ -0,0 +1,29 @@
+package raph
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDirections(t *testing.T) {
+	g := newTestGraph(testEdge{"AB", "A", "B", map[string]float64{"time": 1}})
+
+	for _, c := range []struct {
+		direction, from, to string
+		cost                float64
+	}{{"", "A", "B", 1}, {"out", "B", "A", -1}, {"in", "B", "A", 1}, {"both", "B", "A", 1}} {
+		result := NewQuery(`{"from": "` + c.from + `", "to": "` + c.to + `", "constraint": {"label": "road", "direction": "` + c.direction + `"}, "minimize": ["time"]}`).Run(*g)
+		if result["error"] != nil || fmt.Sprint(result["cost"]) != fmt.Sprint(c.cost) {
+			t.Fatalf("expected the %q path from %s to %s to cost %v, got %v", c.direction, c.from, c.to, c.cost, result)
+		}
+	}
+
+	for _, query := range []string{
+		`{"from": "A", "to": "B", "constraint": {"label": "road", "direction": "sideways"}}`,
+		`{"from": "A", "mode": "isochrone", "budget": 1, "constraint": {"label": "road"}, "selection": {"direction": "sideways"}}`,
+	} {
+		if result := NewQuery(query).Run(*g); result["error"] == nil {
+			t.Fatalf("expected an unknown direction to be rejected, got %v", result)
+		}
+	}
+}
@@ -6,21 +6,24 @@ import (
 
 // Dijkstra instance is used to compute Dijkstra algorithm.
 type Dijkstra struct {
-	G      Graph
+	G      GraphReader
 	Q      []string
 	Costs  map[string]float64
 	PredsV map[string]string
 	PredsE map[string]string
+	turns  bool // whether or not one of the vertices restricts its turns, in which case paths are computed by StateDijkstra
 }
 
-// NewDijkstra initializes and returns a Dijkstra instance with graph g.
-func NewDijkstra(g Graph) *Dijkstra {
-	vertices := g.Vertices
+// NewDijkstra initializes and returns a Dijkstra instance with graph g, which can be a Graph or a GraphView.
+func NewDijkstra(g GraphReader) *Dijkstra {
+	vertices := g.GetVertices()
 
-	// append all vertices to queue
+	// append all vertices to queue, and look for turn restrictions
 	q := make([]string, 0, len(vertices))
+	turns := false
 	for _, vertex := range vertices {
 		q = append(q, vertex.ID)
+		turns = turns || vertex.Turns != nil
 	}
 
 	// initialize all costs with +infinity
@@ -32,7 +35,7 @@ func NewDijkstra(g Graph) *Dijkstra {
 	PredsV := map[string]string{}
 	PredsE := map[string]string{}
 
-	return &Dijkstra{g, q, costs, PredsV, PredsE}
+	return &Dijkstra{g, q, costs, PredsV, PredsE, turns}
 }
 
 // Reset resets the Dijkstra instance for further use.
@@ -68,8 +71,8 @@ func (d *Dijkstra) UpdateDistances(s1, s2, edge string, s1s2Weight float64) {
 	}
 }
 
-// ShortestPath returns a slice of ids with its cost. The value minimized is the sum of specified costs (minimize slice).
-func (d *Dijkstra) ShortestPath(query Query) ([]map[string]interface{}, float64) {
+// ComputeCosts computes the minimal costs from query.From to all vertices, along with their predecessors.
+func (d *Dijkstra) ComputeCosts(query Query) {
 	// init dijkstra
 	d.Reset()
 	d.Costs[query.From] = 0
@@ -83,6 +86,57 @@ func (d *Dijkstra) ShortestPath(query Query) ([]map[string]interface{}, float64)
 			d.UpdateDistances(s1, s2, edge, cost)
 		}
 	}
+}
+
+// ShortestPath returns a slice of ids with its cost. The value minimized is the sum of specified costs (minimize slice).
+func (d *Dijkstra) ShortestPath(query Query) ([]map[string]interface{}, float64) {
+	if d.turns {
+		return NewStateDijkstra(d.G).ShortestPath(query)
+	}
+
+	d.ComputeCosts(query)
+
+	// arrange return variables
+	path := GetPath(query.From, query.To, d.PredsV, d.PredsE)
+	detailedPath := GetDetailedPath(path, d.G)
+	cost := d.Costs[query.To]
+
+	return detailedPath, cost
+}
+
+// FewestHops returns the path crossing the fewest edges with its cost. Paths with the same number of edges are compared on the sum of specified costs (minimize slice).
+func (d *Dijkstra) FewestHops(query Query) ([]map[string]interface{}, float64) {
+	if d.turns {
+		query.Mode = ModeFewestHops
+		return NewStateDijkstra(d.G).ShortestPath(query)
+	}
+
+	// init bfs
+	d.Reset()
+	d.Costs[query.From] = 0
+	hops := map[string]int{query.From: 0}
+	layer := []string{query.From}
+
+	// explore vertices layer by layer until destination is reached
+	for depth := 1; len(layer) > 0 && (query.MaxHops == 0 || depth <= query.MaxHops); depth++ {
+		next := map[string]bool{}
+		for _, s1 := range layer {
+			neighbors, edges := d.G.GetNeighborsWithCostsAndEdges(s1, *query.Constraint, query.Minimize...)
+			for s2, cost := range neighbors {
+				if h, ok := hops[s2]; ok && h < depth {
+					continue
+				}
+				hops[s2] = depth
+				next[s2] = true
+				d.UpdateDistances(s1, s2, edges[s2], cost)
+			}
+		}
+
+		if next[query.To] {
+			break
+		}
+		layer = ToSlice(next)
+	}
 
 	// arrange return variables
 	path := GetPath(query.From, query.To, d.PredsV, d.PredsE)
@@ -97,12 +151,17 @@ func (d *Dijkstra) ShortestPathInverse(query Query) ([]map[string]interface{}, f
 	tmp := query.From
 	query.From = query.To
 	query.To = tmp
-	query.Constraint.Label = "~" + query.Constraint.Label
+	query.Constraint = query.Constraint.Copy()
+	query.Constraint.Direction = query.Constraint.Direction.Inverse()
 	return d.ShortestPath(query)
 }
 
 // ShortestPathOption returns a path (slice of nodes) with its cost. One of the vertices of the path includes the option specified in the query.
 func (d *Dijkstra) ShortestPathOption(query Query) ([]map[string]interface{}, float64) {
+	if d.turns {
+		return NewStateDijkstra(d.G).ShortestPath(query)
+	}
+
 	// compute bi-directional shortest path
 	d.ShortestPath(query)
 	fromCosts, fromPredsV, fromPredsE := d.Costs, d.PredsV, d.PredsE
@@ -112,7 +171,7 @@ func (d *Dijkstra) ShortestPathOption(query Query) ([]map[string]interface{}, fl
 	// select best vertex
 	cost := math.Inf(0)
 	minVertex := "none"
-	for vertexID, vertex := range d.G.Vertices {
+	for vertexID, vertex := range d.G.GetVertices() {
 		if vertexCost, ok := vertex.Costs[query.Option]; ok {
 			pathCost := fromCosts[vertexID] + toCosts[vertexID]
 			if pathCost > 0 && pathCost < cost {
@@ -0,0 +1,85 @@
+package raph
+
+// runDisjoint executes the query in disjoint mode. Paths are computed as a minimum cost flow of K units (2 by default) through edges of unit capacity, and vertices of unit capacity if VertexDisjoint is true.
+func (q Query) runDisjoint(graph GraphReader, turns bool) map[string]interface{} {
+	none := map[string]interface{}{"paths": [][]map[string]interface{}{}, "costs": []float64{}, "cost": -1}
+
+	// flows cannot tell which edge entered a vertex
+	if turns {
+		none["error"] = "disjoint mode does not support turns"
+		return none
+	}
+
+	k := q.K
+	if k == 0 {
+		k = 2
+	}
+
+	n, err := newFlowNetwork(graph, *q.Constraint, "", q.Minimize, q.VertexDisjoint)
+	if err != nil {
+		none["error"] = err.Error()
+		return none
+	}
+	_, okFrom := n.nodes[q.From]
+	t, okTo := n.nodes[q.To]
+	if !okFrom || !okTo || q.From == q.To {
+		return none
+	}
+	s := n.out(q.From)
+
+	n.bound(float64(k + 1))
+	value, cost := n.minCostFlow(s, t, float64(k))
+	if value < float64(k)-epsilon || !q.withinBudget(cost) {
+		return none
+	}
+
+	paths := [][]map[string]interface{}{}
+	costs := []float64{}
+	for i := 0; i < k; i++ {
+		path, pathCost := n.decompose(q.From, s, t)
+		paths = append(paths, GetDetailedPath(path, graph))
+		costs = append(costs, pathCost)
+	}
+
+	return map[string]interface{}{"paths": paths, "costs": costs, "cost": cost}
+}
+
+// decompose removes a unit of flow from node s, leaving vertex from, to node t, and returns the ids of the vertices and edges it crosses along with its cost.
+func (n *flowNetwork) decompose(from string, s, t int) ([]string, float64) {
+	vertices := map[int]string{}
+	for id, node := range n.nodes {
+		vertices[node] = id
+	}
+
+	path := []string{from}
+	cost := 0.0
+	for node := s; node != t; {
+		next := -1
+		for i := range n.arcs[node] {
+			arc := &n.arcs[node][i]
+			if arc.initial-arc.capacity < 1-epsilon {
+				continue
+			}
+
+			arc.capacity++
+			n.arcs[arc.to][arc.rev].capacity--
+			cost += arc.cost
+			if arc.edge != "" {
+				path = append(path, arc.edge)
+			}
+			if id, ok := vertices[arc.to]; ok {
+				path = append(path, id)
+			}
+			next = arc.to
+			break
+		}
+
+		// no flow left
+		if next < 0 {
+			break
+		}
+		node = next
+	}
+
+	return path, cost
+}
@@ -0,0 +1,20 @@
+package raph
+
+import (
+	"testing"
+)
+
+func TestDisjointRejectsTurns(t *testing.T) {
+	g := newTestGraph(testEdge{"SX1", "S", "X", nil}, testEdge{"SX2", "S", "X", nil}, testEdge{"XT1", "X", "T", nil}, testEdge{"XT2", "X", "T", nil})
+	query := NewQuery(`{"from": "S", "to": "T", "mode": "disjoint", "constraint": {"label": "road"}}`)
+
+	if result := query.Run(*g); result["cost"] != 0.0 || len(result["paths"].([][]map[string]interface{})) != 2 {
+		t.Fatalf("expected 2 disjoint paths, got %v", result)
+	}
+
+	x, _ := g.GetVertex("X")
+	x.ForbidTurn("SX1", "XT1", "XT2")
+	if result := query.Run(*g); result["error"] == nil || result["cost"] != -1 {
+		t.Fatalf("expected disjoint mode to reject turns, got %v", result)
+	}
+}
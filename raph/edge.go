@@ -1,10 +1,12 @@
 package raph
 
-// Edge represents an edge instance. It inherits from Vertex structure. Froms & Tos fields are removed from JSON Marshaling.
+// Edge represents an edge instance. It inherits from Vertex structure. Froms & Tos fields are removed from JSON Marshaling. Timetable optionally schedules the crossings of the edge in time-dependent queries.
 type Edge struct {
 	Vertex
-	Froms map[string]bool `json:"-"` // list of vertices from which the edge is reachable
-	Tos   map[string]bool `json:"-"` // list of vertices that the edge can reach
+	Froms     map[string]bool `json:"-"` // list of vertices from which the edge is reachable
+	Tos       map[string]bool `json:"-"` // list of vertices that the edge can reach
+	Timetable Timetable       `json:"timetable,omitempty"`
+	Reserved  float64         `json:"reserved,omitempty"` // capacity held by reservations (see Graph.Reserve)
 }
 
 // NewEdge returns a new edge.
@@ -16,5 +18,18 @@ func NewEdge(id, label, from, to string) *Edge {
 
 // NewMultiEdge returns a new multiedge.
 func NewMultiEdge(id, label string, froms, tos map[string]bool) *Edge {
-	return &Edge{*NewVertex(id, label), froms, tos}
+	return &Edge{*NewVertex(id, label), froms, tos, nil, 0}
+}
+
+// Copy returns a copy of the edge.
+func (e Edge) Copy() *Edge {
+	froms := map[string]bool{}
+	for from, ok := range e.Froms {
+		froms[from] = ok
+	}
+	tos := map[string]bool{}
+	for to, ok := range e.Tos {
+		tos[to] = ok
+	}
+	return &Edge{*e.Vertex.Copy(), froms, tos, e.Timetable.Copy(), e.Reserved}
 }
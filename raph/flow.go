@@ -0,0 +1,365 @@
+package raph
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// epsilon is the residual capacity below which an arc is considered saturated.
+const epsilon = 1e-9
+
+// ErrNegativeCost is returned by MinCostFlow when an edge or a vertex has a negative cost to minimize.
+var ErrNegativeCost = errors.New("negative cost")
+
+// Flow is the result of a flow computation: its value, its cost (minimum cost flows only), the flow crossing each edge (edges without flow are omitted), and the sorted ids of the edges of a minimum cut. If the flow is unbounded, its value is +infinity and the cut is empty.
+type Flow struct {
+	Value float64            `json:"value"`
+	Cost  float64            `json:"cost"`
+	Edges map[string]float64 `json:"edges"`
+	Cut   []string           `json:"cut"`
+}
+
+// MaxFlow returns the maximum flow from vertex from to vertex to, crossing the edges satisfying the constraint. The capacity of the edges is their capacity cost, edges without it are unlimited. The capacity of a multiedge is shared by all its ends.
+func (g Graph) MaxFlow(from, to string, constraint Constraint, capacity string) Flow {
+	n, _ := newFlowNetwork(g, constraint, capacity, nil, false)
+	s, okFrom := n.nodes[from]
+	t, okTo := n.nodes[to]
+	if !okFrom || !okTo || from == to {
+		return Flow{0, 0, map[string]float64{}, []string{}}
+	}
+
+	n.bound(n.unbounded)
+	value := n.maxFlow(s, t)
+	if value >= n.unbounded {
+		return Flow{math.Inf(0), 0, n.flows(), []string{}}
+	}
+	return Flow{value, 0, n.flows(), n.cut(s)}
+}
+
+// MinCostFlow returns the flow of the specified amount from vertex from to vertex to minimizing the sum of specified costs (minimize slice), which are per unit of flow. Edges and capacities are the ones of MaxFlow. If the amount cannot be reached, the maximum flow of minimum cost is returned along with a minimum cut. Costs should be positive, otherwise ErrNegativeCost is returned.
+func (g Graph) MinCostFlow(from, to string, amount float64, constraint Constraint, capacity string, minimize ...string) (Flow, error) {
+	n, err := newFlowNetwork(g, constraint, capacity, minimize, false)
+	if err != nil {
+		return Flow{0, 0, map[string]float64{}, []string{}}, err
+	}
+	s, okFrom := n.nodes[from]
+	t, okTo := n.nodes[to]
+	if !okFrom || !okTo || from == to || amount <= 0 {
+		return Flow{0, 0, map[string]float64{}, []string{}}, nil
+	}
+
+	n.bound(math.Max(n.unbounded, amount+1))
+	value, cost := n.minCostFlow(s, t, amount)
+	if value < amount-epsilon {
+		return Flow{value, cost, n.flows(), n.cut(s)}, nil
+	}
+	return Flow{value, cost, n.flows(), []string{}}, nil
+}
+
+// flowArc is an arc of the residual network.
+type flowArc struct {
+	to       int
+	rev      int     // index of the reverse arc in the arcs of to
+	capacity float64 // residual capacity
+	initial  float64 // capacity before any flow
+	cost     float64 // cost per unit of flow
+	edge     string  // edge whose capacity is carried by the arc, if any
+}
+
+// flowNetwork is the residual network of a graph. Each edge is split into an inbound and an outbound node linked by an arc carrying its capacity and cost, so that the ends of multiedges share them. Arcs leaving the outbound node carry the cost of the neighbors.
+// Vertices can be split the same way, with a unit capacity.
+type flowNetwork struct {
+	nodes     map[string]int // inbound nodes of the vertices
+	split     bool           // whether or not vertices have an outbound node
+	arcs      [][]flowArc
+	unbounded float64 // greater than the sum of the capacities of the limited arcs
+	levels    []int
+	next      []int
+}
+
+// newFlowNetwork builds the residual network of the edges satisfying the constraint, with the sum of specified costs. If capacity is empty, edges have a unit capacity. Unlimited arcs have an infinite capacity until bounded. Negative costs are rejected, as they would break the shortest paths of minCostFlow.
+func newFlowNetwork(r GraphReader, constraint Constraint, capacity string, minimize []string, split bool) (*flowNetwork, error) {
+	n := &flowNetwork{nodes: map[string]int{}, split: split}
+
+	vertices := []string{}
+	for id := range r.GetVertices() {
+		vertices = append(vertices, id)
+	}
+	sort.Strings(vertices)
+	for _, id := range vertices {
+		n.node(id)
+		if split {
+			n.node("")
+			n.link(n.nodes[id], n.nodes[id]+1, "", 1, 0)
+		}
+	}
+
+	// edges crossed under the constraint, and their ends
+	edges := map[string]int{}
+	linked := map[[2]int]bool{}
+	n.unbounded = 1
+	for _, id := range vertices {
+		for _, a := range getArcs(r, id, constraint) {
+			in, ok := edges[a.edge.ID]
+			if !ok {
+				in = n.node("")
+				n.node("")
+				edges[a.edge.ID] = in
+				value, ok := a.edge.Costs[capacity]
+				switch {
+				case capacity == "":
+					value = 1
+				case !ok:
+					value = math.Inf(0)
+				}
+				if !math.IsInf(value, 0) {
+					n.unbounded += value
+				}
+				cost := 0.0
+				for _, c := range minimize {
+					cost += a.edge.Costs[c]
+				}
+				if cost < 0 {
+					return nil, fmt.Errorf("edge %s: %w", a.edge.ID, ErrNegativeCost)
+				}
+				n.link(in, in+1, a.edge.ID, value, cost)
+			}
+
+			if ends := [2]int{n.out(id), in}; !linked[ends] {
+				linked[ends] = true
+				n.link(ends[0], ends[1], "", math.Inf(0), 0)
+			}
+			if ends := [2]int{in + 1, n.nodes[a.neighbor.ID]}; !linked[ends] {
+				linked[ends] = true
+				cost := 0.0
+				for _, c := range minimize {
+					cost += a.neighbor.Costs[c]
+				}
+				if cost < 0 {
+					return nil, fmt.Errorf("vertex %s: %w", a.neighbor.ID, ErrNegativeCost)
+				}
+				n.link(ends[0], ends[1], "", math.Inf(0), cost)
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// out returns the outbound node of the vertex.
+func (n *flowNetwork) out(id string) int {
+	if n.split {
+		return n.nodes[id] + 1
+	}
+	return n.nodes[id]
+}
+
+// bound sets the capacity of the unlimited arcs.
+func (n *flowNetwork) bound(capacity float64) {
+	for _, arcs := range n.arcs {
+		for i := range arcs {
+			if math.IsInf(arcs[i].initial, 0) {
+				arcs[i].capacity, arcs[i].initial = capacity, capacity
+			}
+		}
+	}
+}
+
+// node adds a node to the network, named after the vertex id if any, and returns its index.
+func (n *flowNetwork) node(id string) int {
+	n.arcs = append(n.arcs, []flowArc{})
+	if id != "" {
+		n.nodes[id] = len(n.arcs) - 1
+	}
+	return len(n.arcs) - 1
+}
+
+// link adds an arc with the specified capacity and cost between nodes from and to, along with its reverse arc.
+func (n *flowNetwork) link(from, to int, edge string, capacity, cost float64) {
+	n.arcs[from] = append(n.arcs[from], flowArc{to, len(n.arcs[to]), capacity, capacity, cost, edge})
+	n.arcs[to] = append(n.arcs[to], flowArc{from, len(n.arcs[from]) - 1, 0, 0, -cost, ""})
+}
+
+// maxFlow computes the maximum flow from node s to node t with Dinic algorithm, and returns its value.
+func (n *flowNetwork) maxFlow(s, t int) float64 {
+	value := 0.0
+	for n.level(s, t) {
+		n.next = make([]int, len(n.arcs))
+		for {
+			pushed := n.push(s, t, math.Inf(0))
+			if pushed <= epsilon {
+				break
+			}
+			value += pushed
+			if value >= n.unbounded {
+				return value
+			}
+		}
+	}
+	return value
+}
+
+// level computes the distance of the nodes from node s in the residual network, and returns whether or not node t is reachable.
+func (n *flowNetwork) level(s, t int) bool {
+	n.levels = make([]int, len(n.arcs))
+	for i := range n.levels {
+		n.levels[i] = -1
+	}
+	n.levels[s] = 0
+
+	queue := []int{s}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, arc := range n.arcs[node] {
+			if arc.capacity > epsilon && n.levels[arc.to] < 0 {
+				n.levels[arc.to] = n.levels[node] + 1
+				queue = append(queue, arc.to)
+			}
+		}
+	}
+
+	return n.levels[t] >= 0
+}
+
+// push sends at most limit units of flow from node to node t along increasing levels, and returns the amount sent.
+func (n *flowNetwork) push(node, t int, limit float64) float64 {
+	if node == t {
+		return limit
+	}
+
+	for ; n.next[node] < len(n.arcs[node]); n.next[node]++ {
+		arc := &n.arcs[node][n.next[node]]
+		if arc.capacity <= epsilon || n.levels[arc.to] != n.levels[node]+1 {
+			continue
+		}
+		if pushed := n.push(arc.to, t, math.Min(limit, arc.capacity)); pushed > epsilon {
+			arc.capacity -= pushed
+			n.arcs[arc.to][arc.rev].capacity += pushed
+			return pushed
+		}
+	}
+
+	return 0
+}
+
+// minCostFlow sends at most amount units of flow from node s to node t along successive shortest paths, and returns the amount sent with its cost. Shortest paths are computed by Dijkstra algorithm on costs reduced by node potentials, which keeps them positive as long as the costs are.
+func (n *flowNetwork) minCostFlow(s, t int, amount float64) (value, cost float64) {
+	potentials := make([]float64, len(n.arcs))
+
+	for value < amount-epsilon {
+		distances, preds := n.shortestPaths(s, potentials)
+		if math.IsInf(distances[t], 0) {
+			break
+		}
+		for node, distance := range distances {
+			if !math.IsInf(distance, 0) {
+				potentials[node] += distance
+			}
+		}
+
+		// bottleneck of the path
+		pushed := amount - value
+		for node := t; node != s; {
+			arc := n.arcs[preds[node][0]][preds[node][1]]
+			pushed = math.Min(pushed, arc.capacity)
+			node = preds[node][0]
+		}
+
+		for node := t; node != s; {
+			arc := &n.arcs[preds[node][0]][preds[node][1]]
+			arc.capacity -= pushed
+			n.arcs[arc.to][arc.rev].capacity += pushed
+			cost += pushed * arc.cost
+			node = preds[node][0]
+		}
+		value += pushed
+	}
+
+	return value, cost
+}
+
+// shortestPaths returns the reduced distances of the nodes from node s in the residual network, and the arc reaching each node as a pair of node and index.
+func (n *flowNetwork) shortestPaths(s int, potentials []float64) ([]float64, [][2]int) {
+	distances := make([]float64, len(n.arcs))
+	for i := range distances {
+		distances[i] = math.Inf(0)
+	}
+	preds := make([][2]int, len(n.arcs))
+	done := make([]bool, len(n.arcs))
+
+	distances[s] = 0
+	queue := &nodeQueue{{s, 0}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(nodeItem)
+		if done[item.node] {
+			continue
+		}
+		done[item.node] = true
+
+		for i, arc := range n.arcs[item.node] {
+			if arc.capacity <= epsilon {
+				continue
+			}
+			distance := item.distance + arc.cost + potentials[item.node] - potentials[arc.to]
+			if distance < distances[arc.to] {
+				distances[arc.to] = distance
+				preds[arc.to] = [2]int{item.node, i}
+				heap.Push(queue, nodeItem{arc.to, distance})
+			}
+		}
+	}
+
+	return distances, preds
+}
+
+// flows returns the flow crossing each edge.
+func (n *flowNetwork) flows() map[string]float64 {
+	flows := map[string]float64{}
+	for _, arcs := range n.arcs {
+		for _, arc := range arcs {
+			if arc.edge != "" && arc.initial-arc.capacity > epsilon {
+				flows[arc.edge] = arc.initial - arc.capacity
+			}
+		}
+	}
+	return flows
+}
+
+// cut returns the sorted edges separating the nodes reachable from node s in the residual network from the other ones.
+func (n *flowNetwork) cut(s int) []string {
+	n.level(s, s)
+
+	cut := []string{}
+	for node, arcs := range n.arcs {
+		for _, arc := range arcs {
+			if arc.edge != "" && n.levels[node] >= 0 && n.levels[arc.to] < 0 {
+				cut = append(cut, arc.edge)
+			}
+		}
+	}
+	sort.Strings(cut)
+	return cut
+}
+
+// nodeItem is an element of the priority queue of nodes.
+type nodeItem struct {
+	node     int
+	distance float64
+}
+
+// nodeQueue is a priority queue of nodes ordered by distance. It implements heap.Interface.
+type nodeQueue []nodeItem
+
+func (q nodeQueue) Len() int            { return len(q) }
+func (q nodeQueue) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(nodeItem)) }
+func (q *nodeQueue) Pop() interface{} {
+	item := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return item
+}
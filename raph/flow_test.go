@@ -0,0 +1,59 @@
+package raph
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMaxFlow(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"s1", "s", "1", map[string]float64{"capacity": 16}},
+		testEdge{"s2", "s", "2", map[string]float64{"capacity": 13}},
+		testEdge{"13", "1", "3", map[string]float64{"capacity": 12}},
+		testEdge{"21", "2", "1", map[string]float64{"capacity": 4}},
+		testEdge{"24", "2", "4", map[string]float64{"capacity": 14}},
+		testEdge{"32", "3", "2", map[string]float64{"capacity": 9}},
+		testEdge{"3t", "3", "t", map[string]float64{"capacity": 20}},
+		testEdge{"43", "4", "3", map[string]float64{"capacity": 7}},
+		testEdge{"4t", "4", "t", map[string]float64{"capacity": 4}},
+	)
+
+	flow := g.MaxFlow("s", "t", *NewConstraint("road"), "capacity")
+	if flow.Value != 23 || !reflect.DeepEqual(flow.Cut, []string{"13", "43", "4t"}) {
+		t.Fatalf("expected a flow of 23 cut by 13, 43 and 4t, got %v", flow)
+	}
+	if flow.Edges["3t"]+flow.Edges["4t"] != 23 || flow.Edges["s1"]+flow.Edges["s2"] != 23 {
+		t.Fatalf("expected 23 units leaving s and reaching t, got %v", flow.Edges)
+	}
+
+	if flow := g.MaxFlow("s", "t", *NewConstraint("road"), "unknown"); !math.IsInf(flow.Value, 1) || len(flow.Cut) != 0 {
+		t.Fatalf("expected an unbounded flow without capacities, got %v", flow)
+	}
+}
+
+func TestMinCostFlow(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"SA", "S", "A", map[string]float64{"capacity": 2, "price": 1}},
+		testEdge{"AT", "A", "T", map[string]float64{"capacity": 1, "price": 1}},
+		testEdge{"AB", "A", "B", map[string]float64{"capacity": 2, "price": 1}},
+		testEdge{"SB", "S", "B", map[string]float64{"capacity": 2, "price": 3}},
+		testEdge{"BT", "B", "T", map[string]float64{"capacity": 3, "price": 1}},
+	)
+
+	for _, c := range []struct {
+		amount, value, cost float64
+		cut                 []string
+	}{{1, 1, 2, []string{}}, {3, 3, 9, []string{}}, {4, 4, 13, []string{}}, {5, 4, 13, []string{"SA", "SB"}}} {
+		flow, err := g.MinCostFlow("S", "T", c.amount, *NewConstraint("road"), "capacity", "price")
+		if err != nil || flow.Value != c.value || flow.Cost != c.cost || !reflect.DeepEqual(flow.Cut, c.cut) {
+			t.Fatalf("expected a flow of %v costing %v cut by %v for %v units, got %v, %v", c.value, c.cost, c.cut, c.amount, flow, err)
+		}
+	}
+
+	g.Edges["SB"].SetCost("price", -3)
+	if _, err := g.MinCostFlow("S", "T", 1, *NewConstraint("road"), "capacity", "price"); !errors.Is(err, ErrNegativeCost) {
+		t.Fatalf("expected ErrNegativeCost, got %v", err)
+	}
+}
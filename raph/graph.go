@@ -1,5 +1,9 @@
 package raph
 
+import (
+	"sort"
+)
+
 // Graph represents a graph instance.
 type Graph struct {
 	Vertices    map[string]*Vertex
@@ -12,6 +16,21 @@ func NewGraph() *Graph {
 	return &Graph{map[string]*Vertex{}, map[string]*Edge{}, map[string][]string{}}
 }
 
+// Copy returns a deep copy of the graph.
+func (g Graph) Copy() *Graph {
+	graph := NewGraph()
+	for id, vertex := range g.Vertices {
+		graph.Vertices[id] = vertex.Copy()
+	}
+	for id, edge := range g.Edges {
+		graph.Edges[id] = edge.Copy()
+	}
+	for key, connections := range g.Connections {
+		graph.Connections[key] = append([]string{}, connections...)
+	}
+	return graph
+}
+
 // hasVertex returns whether or not the graph contains the vertex specified by its id.
 func (g *Graph) hasVertex(id string) bool {
 	_, ok := g.Vertices[id]
@@ -43,8 +62,50 @@ func (g *Graph) AddEdge(e *Edge) {
 	}
 }
 
+// RemoveEdge removes an edge from the graph along with its connections.
+func (g *Graph) RemoveEdge(id string) {
+	e, ok := g.Edges[id]
+	if !ok {
+		return
+	}
+
+	for from := range e.Froms {
+		g.Disconnect(from, e.ID, e.Label)
+	}
+
+	for to := range e.Tos {
+		g.Disconnect(to, e.ID, "~"+e.Label)
+	}
+
+	delete(g.Connections, e.ID+":"+e.Label)
+	delete(g.Connections, e.ID+":~"+e.Label)
+	delete(g.Edges, id)
+}
+
+// RemoveVertex removes a vertex from the graph and detaches it from the edges it was connected to.
+func (g *Graph) RemoveVertex(id string) {
+	if !g.hasVertex(id) {
+		return
+	}
+
+	for _, e := range g.Edges {
+		if e.Froms[id] {
+			g.Disconnect(e.ID, id, "~"+e.Label)
+			delete(g.Connections, id+":"+e.Label)
+			delete(e.Froms, id)
+		}
+		if e.Tos[id] {
+			g.Disconnect(e.ID, id, e.Label)
+			delete(g.Connections, id+":~"+e.Label)
+			delete(e.Tos, id)
+		}
+	}
+
+	delete(g.Vertices, id)
+}
+
 // GetConnections returns reachable vertices or edges from specified edge or vertex, respectively.
-func (g *Graph) GetConnections(id, label string) []string {
+func (g Graph) GetConnections(id, label string) []string {
 	return g.Connections[id+":"+label]
 }
 
@@ -54,96 +115,122 @@ func (g *Graph) Connect(from, to, label string) {
 	g.Connections[key] = append(g.Connections[key], to)
 }
 
-// GetNeighbors retrieves neighbors of vertex under specified constraints.
-func (g Graph) GetNeighbors(vertex string, constraint Constraint) map[string]bool {
-	neighbors := map[string]bool{}
-
-	// retrieve outgoing edges with label
-	edges := g.GetConnections(vertex, constraint.Label)
-
-	for _, edge := range edges {
-		// assert that edge satifies constraint
-		if g.Edges[edge].Satisfies(*constraint.Edge) {
-			// retrieve edge ends
-			vertices := g.GetConnections(edge, constraint.Label)
-
-			for _, neighbor := range vertices {
-				// assert that vertex satifies constraint
-				if g.Vertices[neighbor].Satisfies(*constraint.Vertex) {
-					// add vertex to neighbors
-					neighbors[neighbor] = true
-				}
-			}
+// Disconnect removes specified connection from the graph.
+func (g *Graph) Disconnect(from, to, label string) {
+	key := from + ":" + label
+	connections := g.Connections[key]
+	for i, id := range connections {
+		if id == to {
+			connections = append(connections[:i], connections[i+1:]...)
+			break
 		}
 	}
 
-	return neighbors
+	if len(connections) == 0 {
+		delete(g.Connections, key)
+	} else {
+		g.Connections[key] = connections
+	}
+}
+
+// GetVertices returns all vertices of the graph indexed by id.
+func (g Graph) GetVertices() map[string]*Vertex {
+	return g.Vertices
+}
+
+// GetVertex returns the vertex with specified id.
+func (g Graph) GetVertex(id string) (*Vertex, bool) {
+	vertex, ok := g.Vertices[id]
+	return vertex, ok
+}
+
+// GetEdge returns the edge with specified id.
+func (g Graph) GetEdge(id string) (*Edge, bool) {
+	edge, ok := g.Edges[id]
+	return edge, ok
+}
+
+// GetNeighbors retrieves neighbors of vertex under specified constraints.
+func (g Graph) GetNeighbors(vertex string, constraint Constraint) map[string]bool {
+	return getNeighbors(g, vertex, constraint)
 }
 
 // GetNeighborsWithCostsAndEdges returns reachable vertices. For each neighbor, it returns with the minimal cost and the crossed edge (in the case of multiedges).
 func (g Graph) GetNeighborsWithCostsAndEdges(vertex string, constraint Constraint, minimize ...string) (map[string]float64, map[string]string) {
-	weights := map[string]float64{}
-	crossedEdges := map[string]string{}
-
-	// retrieve outgoing edges with label
-	edges := g.GetConnections(vertex, constraint.Label)
-
-	for _, e := range edges {
-		edge := g.Edges[e]
-
-		// assert that edge satifies constraint
-		if edge.Satisfies(*constraint.Edge) {
-			vertices := g.GetConnections(edge.ID, constraint.Label)
-
-			for _, n := range vertices {
-				neighbor := g.Vertices[n]
-
-				// assert that vertex satifies constraint
-				if neighbor.Satisfies(*constraint.Vertex) {
-					// compute potential cost of crossing vertex+edge
-					potentialCost := 0.0
-					for _, cost := range minimize {
-						potentialCost += edge.Costs[cost] + neighbor.Costs[cost]
-					}
-
-					// compare potential cost to actual cost
-					cost, ok := weights[neighbor.ID]
-					if !ok || potentialCost < cost {
-						weights[neighbor.ID] = potentialCost
-						crossedEdges[neighbor.ID] = edge.ID
-					}
-				}
-			}
-		}
-	}
-	return weights, crossedEdges
+	return getNeighborsWithCostsAndEdges(g, vertex, constraint, minimize...)
+}
+
+// AccessLimits bounds the exploration of ListAccessibleVertices. Zero values mean no limit. The budget applies to the sum of Minimize costs.
+type AccessLimits struct {
+	MaxDepth int
+	Budget   float64
+	Minimize []string
 }
 
-// GetAccessibleVertices returns accessible vertices from vertex using private method getAccessibleVerticesRecursive. selectionConstraint applies only on the Vertex.
+// GetAccessibleVertices returns accessible vertices from vertex. selectionConstraint applies only on the Vertex.
 func (g Graph) GetAccessibleVertices(vertex string, traversalConstraint, selectionConstraint Constraint) map[string]bool {
-	// no vertex is accessible at first
 	accessibleVertices := map[string]bool{}
-
-	// update accessibleVertices with recursive calls
-	if g.hasVertex(vertex) {
-		g.getAccessibleVerticesRecursive(vertex, traversalConstraint, selectionConstraint, accessibleVertices)
+	for _, v := range g.ListAccessibleVertices(vertex, traversalConstraint, selectionConstraint, AccessLimits{}) {
+		accessibleVertices[v] = true
 	}
-
 	return accessibleVertices
 }
 
-// getAccessibleVerticesRecursive adds accessible vertices from vertex to accessibleVertices.
-func (g Graph) getAccessibleVerticesRecursive(vertex string, traversalConstraint, selectionConstraint Constraint, accessibleVertices map[string]bool) {
-	// inform that the vertex is accessible
-	if g.Vertices[vertex].Satisfies(*selectionConstraint.Vertex) {
-		accessibleVertices[vertex] = true
+// ListAccessibleVertices returns accessible vertices from vertex within the limits. selectionConstraint applies only on the Vertex.
+// Without budget, vertices are explored breadth first and returned in that order, neighbors being sorted by id. With a budget, they are returned by increasing cost, then id.
+func (g Graph) ListAccessibleVertices(vertex string, traversalConstraint, selectionConstraint Constraint, limits AccessLimits) []string {
+	accessibleVertices := []string{}
+	if !g.hasVertex(vertex) {
+		return accessibleVertices
 	}
 
-	// recursive call on all neighbors if not done yet
-	neighbors := g.GetNeighbors(vertex, traversalConstraint)
-	for neighbor := range neighbors {
-		if _, ok := accessibleVertices[neighbor]; !ok {
-			g.getAccessibleVerticesRecursive(neighbor, traversalConstraint, selectionConstraint, accessibleVertices)
+	// costs are needed, use a shortest path search bounded by the budget
+	if limits.Budget > 0 {
+		query := Query{From: vertex, Constraint: &traversalConstraint, Minimize: limits.Minimize, Mode: ModeIsochrone, Selection: &selectionConstraint, MaxHops: limits.MaxDepth, Budget: limits.Budget}
+		tree := NewStateDijkstra(g).ShortestPathTree(query)
+		for id := range tree {
+			accessibleVertices = append(accessibleVertices, id)
 		}
+		sort.Slice(accessibleVertices, func(i, j int) bool {
+			a, b := tree[accessibleVertices[i]], tree[accessibleVertices[j]]
+			return a.Cost < b.Cost || (a.Cost == b.Cost && accessibleVertices[i] < accessibleVertices[j])
+		})
+		return accessibleVertices
+	}
+
+	// breadth first search, vertices are marked as visited whether or not they satisfy the selection
+	visited := map[string]bool{vertex: true}
+	layer := []string{vertex}
+	for depth := 0; len(layer) > 0; depth++ {
+		next := []string{}
+		for _, v := range layer {
+			if selectionConstraint.Vertex.Accepts(*g.Vertices[v]) {
+				accessibleVertices = append(accessibleVertices, v)
+			}
+			if limits.MaxDepth > 0 && depth >= limits.MaxDepth {
+				continue
+			}
+
+			neighbors := ToSlice(g.GetNeighbors(v, traversalConstraint))
+			sort.Strings(neighbors)
+			for _, neighbor := range neighbors {
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		layer = next
+	}
+
+	return accessibleVertices
+}
+
+// GetReachableVertices returns the vertices accessible from vertex within the budget, with the minimal sum of specified costs (minimize slice) to reach them. selectionConstraint applies only on the Vertex. The budget should be positive.
+func (g Graph) GetReachableVertices(vertex string, traversalConstraint, selectionConstraint Constraint, budget float64, minimize ...string) (map[string]float64, error) {
+	query := Query{From: vertex, Constraint: &traversalConstraint, Minimize: minimize, Mode: ModeIsochrone, Selection: &selectionConstraint, Budget: budget}
+	if err := query.Validate(); err != nil {
+		return map[string]float64{}, err
 	}
+	return query.runTree(g, hasTurns(g))["vertices"].(map[string]float64), nil
 }
@@ -0,0 +1,25 @@
+package raph
+
+// testEdge is an edge of a test graph along with its costs.
+type testEdge struct {
+	id, from, to string
+	costs        map[string]float64
+}
+
+// newTestGraph returns a graph of the specified edges, labeled road, between vertices labeled node.
+func newTestGraph(edges ...testEdge) *Graph {
+	g := NewGraph()
+	for _, e := range edges {
+		for _, id := range []string{e.from, e.to} {
+			if _, ok := g.GetVertex(id); !ok {
+				g.AddVertex(NewVertex(id, "node"))
+			}
+		}
+		edge := NewEdge(e.id, "road", e.from, e.to)
+		for key, value := range e.costs {
+			edge.SetCost(key, value)
+		}
+		g.AddEdge(edge)
+	}
+	return g
+}
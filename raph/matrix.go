@@ -0,0 +1,144 @@
+package raph
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// MatrixQuery represents a many-to-many query. It computes the costs of the shortest paths between each origin and each destination, with the constraints and requirements of the embedded query (From and To are ignored).
+// Origins and destinations are lists of vertex ids, or the vertices satisfying OriginSelection and DestinationSelection. Searches from origins are run in parallel by Workers goroutines (number of CPUs by default).
+type MatrixQuery struct {
+	Query
+	Origins              []string    `json:"origins"`
+	Destinations         []string    `json:"destinations"`
+	OriginSelection      *Constraint `json:"originSelection"`
+	DestinationSelection *Constraint `json:"destinationSelection"`
+	Paths                bool        `json:"paths"`
+	Workers              int         `json:"workers"`
+}
+
+// NewMatrixQuery returns a matrix query instance representing the specified JSON string.
+func NewMatrixQuery(queryString string) *MatrixQuery {
+	query := MatrixQuery{Query: Query{Constraint: &Constraint{Vertex: &VertexConstraint{}, Edge: &Component{}}}}
+	err := json.Unmarshal([]byte(queryString), &query)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return &query
+}
+
+// Validate returns an error if the matrix query cannot be run. Matrices only support the shortest mode.
+func (q MatrixQuery) Validate() error {
+	if err := q.Query.Validate(); err != nil {
+		return err
+	}
+	if q.Mode != "" && q.Mode != ModeShortest {
+		return fmt.Errorf("matrix queries do not support %s mode", q.Mode)
+	}
+	return nil
+}
+
+// Run executes and returns the matrix query on the specified graph. costs[i][j] is the cost from origins[i] to destinations[j], or -1 if no path exists. If Paths is true, paths[i][j] is the corresponding slice of ids.
+func (q MatrixQuery) Run(graph GraphReader) map[string]interface{} {
+	if err := q.Validate(); err != nil {
+		return map[string]interface{}{"costs": [][]float64{}, "error": err.Error()}
+	}
+
+	// hide avoided vertices and edges, and edges without enough capacity
+	if len(q.Avoid) > 0 {
+		graph = NewAvoidView(graph, q.Avoid...)
+	}
+	q.Query = q.Query.withDemand()
+
+	origins := selectVertices(graph, q.Origins, q.OriginSelection)
+	destinations := selectVertices(graph, q.Destinations, q.DestinationSelection)
+	costs := make([][]float64, len(origins))
+	paths := make([][][]string, len(origins))
+
+	// dispatch origins to workers
+	workers := q.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := NewStateDijkstra(graph)
+			for i := range indexes {
+				costs[i], paths[i] = q.runOrigin(d, origins[i], destinations)
+			}
+		}()
+	}
+	for i := range origins {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	res := map[string]interface{}{"origins": origins, "destinations": destinations, "costs": costs}
+	if q.Paths {
+		res["paths"] = paths
+	}
+	return res
+}
+
+// runOrigin returns the costs and paths from origin to each destination.
+func (q MatrixQuery) runOrigin(d *StateDijkstra, origin string, destinations []string) ([]float64, [][]string) {
+	costs := make([]float64, len(destinations))
+	paths := make([][]string, len(destinations))
+
+	query := q.Query
+	query.From = origin
+	search, err := newStateSearch(query)
+	if err != nil {
+		return costs, paths
+	}
+	best := d.runAll(search)
+
+	for j, destination := range destinations {
+		key, ok := best[destination]
+		if !ok || d.Costs[key] == math.Inf(0) {
+			costs[j] = -1
+			paths[j] = []string{}
+			continue
+		}
+		costs[j] = q.Objective.value(d.Costs[key])
+		if q.Paths {
+			paths[j], _ = d.GetPath(key)
+		}
+	}
+
+	return costs, paths
+}
+
+// selectVertices returns the specified vertices existing in the graph, or the sorted ids of the vertices satisfying the selection if no vertex is specified.
+func selectVertices(graph GraphReader, ids []string, selection *Constraint) []string {
+	selected := []string{}
+	if len(ids) > 0 {
+		for _, id := range ids {
+			if _, ok := graph.GetVertex(id); ok {
+				selected = append(selected, id)
+			}
+		}
+		return selected
+	}
+
+	if selection == nil {
+		return selected
+	}
+	for id, vertex := range graph.GetVertices() {
+		if selection.Vertex == nil || selection.Vertex.Accepts(*vertex) {
+			selected = append(selected, id)
+		}
+	}
+	sort.Strings(selected)
+	return selected
+}
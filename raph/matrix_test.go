@@ -0,0 +1,70 @@
+package raph
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestMatrixWorkers(t *testing.T) {
+	// 6x6 grid with costs varying along the rows and columns
+	edges := []testEdge{}
+	n := 6
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			id := strconv.Itoa(i*n + j)
+			if j+1 < n {
+				next := strconv.Itoa(i*n + j + 1)
+				edges = append(edges, testEdge{id + "-" + next, id, next, map[string]float64{"time": float64(i + 1)}})
+			}
+			if i+1 < n {
+				next := strconv.Itoa((i+1)*n + j)
+				edges = append(edges, testEdge{id + "-" + next, id, next, map[string]float64{"time": float64(j + 1)}})
+			}
+		}
+	}
+	g := newTestGraph(edges...)
+
+	query := `{"originSelection": {"vertex": {"labels": ["node"]}}, "destinationSelection": {"vertex": {"labels": ["node"]}}, "constraint": {"label": "road"}, "minimize": ["time"], "paths": true, "workers": `
+	sequential := NewMatrixQuery(query + `1}`).Run(*g)
+	parallel := NewMatrixQuery(query + `8}`).Run(*g)
+	if !reflect.DeepEqual(sequential, parallel) {
+		t.Fatalf("expected workers not to change the matrix")
+	}
+
+	origins, costs := parallel["origins"].([]string), parallel["costs"].([][]float64)
+	destinations := parallel["destinations"].([]string)
+	if len(origins) != n*n || len(destinations) != n*n {
+		t.Fatalf("expected %d origins and destinations, got %d and %d", n*n, len(origins), len(destinations))
+	}
+	for i, origin := range origins {
+		for j, destination := range destinations {
+			result := NewQuery(`{"from": "` + origin + `", "to": "` + destination + `", "constraint": {"label": "road"}, "minimize": ["time"]}`).Run(*g)
+			// unreachable and identical ends cost an int
+			cost, ok := result["cost"].(float64)
+			if !ok {
+				cost = float64(result["cost"].(int))
+			}
+			if costs[i][j] != cost {
+				t.Fatalf("expected the cost from %s to %s to be %v, got %v", origin, destination, result["cost"], costs[i][j])
+			}
+		}
+	}
+}
+
+func TestMatrixValidate(t *testing.T) {
+	g := newTestGraph(testEdge{"AB", "A", "B", map[string]float64{"time": 1}})
+	g.Edges["AB"].AddDeparture(10, 2)
+
+	for _, mode := range []string{"fewestHops", "tree", "isochrone", "disjoint"} {
+		if result := NewMatrixQuery(`{"origins": ["A"], "destinations": ["B"], "constraint": {"label": "road"}, "budget": 10, "mode": "` + mode + `"}`).Run(*g); result["error"] == nil {
+			t.Fatalf("expected matrices to reject %s mode, got %v", mode, result)
+		}
+	}
+
+	// waits for the departure at 10
+	result := NewMatrixQuery(`{"origins": ["A"], "destinations": ["B"], "constraint": {"label": "road"}, "departAt": 8}`).Run(*g)
+	if costs := result["costs"].([][]float64); costs[0][0] != 4 {
+		t.Fatalf("expected the matrix to follow timetables, got %v", result)
+	}
+}
@@ -0,0 +1,106 @@
+package raph
+
+import (
+	"fmt"
+)
+
+// MergePolicy defines how Merge resolves vertices or edges sharing the same id.
+type MergePolicy int
+
+const (
+	// MergeKeep keeps the vertex or edge of the receiver graph.
+	MergeKeep MergePolicy = iota
+	// MergeReplace replaces the vertex or edge by the one of the other graph.
+	MergeReplace
+	// MergeUnion merges props, costs, turns, windows, timetables and ends of both vertices or edges. Costs of the other graph prevail. Labels should be equal.
+	MergeUnion
+	// MergeFail makes Merge return an error.
+	MergeFail
+)
+
+// Merge returns a new graph containing the vertices and edges of both graphs. Vertices and edges are copied and connections are rebuilt. Conflicts on ids are resolved according to policy. A vertex and an edge cannot share the same id.
+func (g Graph) Merge(other Graph, policy MergePolicy) (*Graph, error) {
+	vertices := map[string]*Vertex{}
+	edges := map[string]*Edge{}
+
+	for id, vertex := range g.Vertices {
+		vertices[id] = vertex.Copy()
+	}
+	for id, edge := range g.Edges {
+		edges[id] = edge.Copy()
+	}
+
+	for id, vertex := range other.Vertices {
+		if _, ok := edges[id]; ok {
+			return nil, fmt.Errorf("%w: %s is both a vertex and an edge", ErrDuplicateID, id)
+		}
+
+		current, ok := vertices[id]
+		if !ok {
+			vertices[id] = vertex.Copy()
+			continue
+		}
+
+		switch policy {
+		case MergeReplace:
+			vertices[id] = vertex.Copy()
+		case MergeUnion:
+			if current.Label != vertex.Label {
+				return nil, fmt.Errorf("%w: vertex %s is labeled %s and %s", ErrLabelConflict, id, current.Label, vertex.Label)
+			}
+			current.Merge(vertex.Component)
+			if vertex.Turns != nil {
+				if current.Turns == nil {
+					current.Turns = NewTurns()
+				}
+				current.Turns.Merge(*vertex.Turns)
+			}
+			current.Windows = current.Windows.Merge(vertex.Windows)
+		case MergeFail:
+			return nil, fmt.Errorf("%w: vertex %s", ErrDuplicateID, id)
+		}
+	}
+
+	for id, edge := range other.Edges {
+		if _, ok := vertices[id]; ok {
+			return nil, fmt.Errorf("%w: %s is both a vertex and an edge", ErrDuplicateID, id)
+		}
+
+		current, ok := edges[id]
+		if !ok {
+			edges[id] = edge.Copy()
+			continue
+		}
+
+		switch policy {
+		case MergeReplace:
+			edges[id] = edge.Copy()
+		case MergeUnion:
+			if current.Label != edge.Label {
+				return nil, fmt.Errorf("%w: edge %s is labeled %s and %s", ErrLabelConflict, id, current.Label, edge.Label)
+			}
+			current.Merge(edge.Component)
+			current.Timetable = current.Timetable.Merge(edge.Timetable)
+			current.Reserved = edge.Reserved
+			for from := range edge.Froms {
+				current.Froms[from] = true
+			}
+			for to := range edge.Tos {
+				current.Tos[to] = true
+			}
+		case MergeFail:
+			return nil, fmt.Errorf("%w: edge %s", ErrDuplicateID, id)
+		}
+	}
+
+	// rebuild connections once all vertices are known
+	graph := NewGraph()
+	for _, vertex := range vertices {
+		graph.AddVertex(vertex)
+	}
+	for _, edge := range edges {
+		graph.AddEdge(edge)
+	}
+
+	return graph, nil
+}
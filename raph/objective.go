@@ -0,0 +1,94 @@
+package raph
+
+import (
+	"math"
+)
+
+// Objective defines how the costs of the crossed edges and vertices combine into the cost of a path.
+type Objective string
+
+const (
+	// ObjectiveSum minimizes the sum of the costs. It is the default objective.
+	ObjectiveSum Objective = "sum"
+	// ObjectiveMaximin maximizes the minimal cost of the crossed edges, e.g. the smallest capacity of the path.
+	ObjectiveMaximin Objective = "maximin"
+	// ObjectiveMinimax minimizes the maximal cost of the crossed edges, e.g. the longest leg of the path.
+	ObjectiveMinimax Objective = "minimax"
+	// ObjectiveProduct maximizes the product of the costs of the crossed edges and vertices, which should be probabilities, e.g. the on-time probability of the path.
+	ObjectiveProduct Objective = "product"
+)
+
+// isSum returns whether or not the objective sums costs.
+func (o Objective) isSum() bool {
+	return o == "" || o == ObjectiveSum
+}
+
+// isBottleneck returns whether or not the objective only depends on the worst edge of the path.
+func (o Objective) isBottleneck() bool {
+	return o == ObjectiveMaximin || o == ObjectiveMinimax
+}
+
+// known returns whether or not the objective exists.
+func (o Objective) known() bool {
+	return o.isSum() || o.isBottleneck() || o == ObjectiveProduct
+}
+
+// initial returns the cost of a path without any edge. Costs are minimized, so bottleneck objectives start from -infinity.
+func (o Objective) initial() float64 {
+	if o.isBottleneck() {
+		return math.Inf(-1)
+	}
+	return 0
+}
+
+// weight returns the weight of the arc. Bottleneck objectives only consider the edge, and maximin negates its cost so that it is minimized, edges without any of the costs being unlimited. The product objective sums the negated logarithms of the costs, missing costs being probabilities of 1. Arcs with a probability outside (0, 1] weigh +infinity, so that they are never crossed.
+func (o Objective) weight(a arc, minimize []string) float64 {
+	switch o {
+	case ObjectiveMaximin:
+		if !a.hasEdgeCost(minimize) {
+			return math.Inf(-1)
+		}
+		return -a.edgeCost(minimize)
+	case ObjectiveMinimax:
+		return a.edgeCost(minimize)
+	case ObjectiveProduct:
+		weight := 0.0
+		for _, c := range minimize {
+			for _, costs := range []map[string]float64{a.edge.Costs, a.neighbor.Costs} {
+				probability, ok := costs[c]
+				if !ok {
+					continue
+				}
+				if !(probability > 0 && probability <= 1) {
+					return math.Inf(0)
+				}
+				weight -= math.Log(probability)
+			}
+		}
+		return weight
+	}
+	return a.cost(minimize)
+}
+
+// combine returns the cost of a path of the specified cost extended by a transition of the specified weight.
+func (o Objective) combine(cost, weight float64) float64 {
+	if o.isBottleneck() {
+		return math.Max(cost, weight)
+	}
+	return cost + weight
+}
+
+// value returns the cost of a path as returned by queries. Paths without limiting edge cost 0 with bottleneck objectives, since +infinity means that there is no path.
+func (o Objective) value(cost float64) float64 {
+	switch {
+	case o.isSum():
+		return cost
+	case o == ObjectiveProduct:
+		return math.Exp(-cost)
+	case math.IsInf(cost, -1):
+		return 0
+	case o == ObjectiveMaximin:
+		return -cost
+	}
+	return cost
+}
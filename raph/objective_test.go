@@ -0,0 +1,48 @@
+package raph
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProductInvalidProbabilities(t *testing.T) {
+	for _, invalid := range []float64{-0.5, 2, math.NaN()} {
+		g := newTestGraph(
+			testEdge{"SA", "S", "A", map[string]float64{"ontime": 0.9}},
+			testEdge{"AT", "A", "T", map[string]float64{"ontime": 0.9}},
+			testEdge{"SB", "S", "B", map[string]float64{"ontime": invalid}},
+			testEdge{"BT", "B", "T", map[string]float64{"ontime": 1}},
+		)
+
+		result := NewQuery(`{"from": "S", "to": "T", "constraint": {"label": "road"}, "minimize": ["ontime"], "objective": "product"}`).Run(*g)
+		cost := result["cost"].(float64)
+		if path := ExtractIDS(result["path"].([]map[string]interface{})); len(path) != 5 || path[2] != "A" || math.Abs(cost-0.81) > 1e-9 {
+			t.Fatalf("expected the path through A with probability %v on SB, got %v", invalid, result)
+		}
+	}
+}
+
+func TestBottleneckObjectives(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"SA", "S", "A", map[string]float64{"size": 5, "time": 5}},
+		testEdge{"AT", "A", "T", map[string]float64{"time": 1}},
+		testEdge{"SB", "S", "B", map[string]float64{"size": 2, "time": 2}},
+		testEdge{"BT", "B", "T", map[string]float64{"size": 2, "time": 3}},
+	)
+
+	for _, c := range []struct {
+		objective, minimize, through string
+		cost                         float64
+	}{{"maximin", "size", "A", 5}, {"minimax", "time", "B", 3}} {
+		result := NewQuery(`{"from": "S", "to": "T", "constraint": {"label": "road"}, "minimize": ["` + c.minimize + `"], "objective": "` + c.objective + `"}`).Run(*g)
+		if path := ExtractIDS(result["path"].([]map[string]interface{})); len(path) != 5 || path[2] != c.through || result["cost"] != c.cost {
+			t.Fatalf("expected the %s path through %s to cost %v, got %v", c.objective, c.through, c.cost, result)
+		}
+	}
+
+	// no edge limits the path
+	result := NewQuery(`{"from": "A", "to": "T", "constraint": {"label": "road"}, "minimize": ["size"], "objective": "maximin"}`).Run(*g)
+	if len(result["path"].([]map[string]interface{})) != 3 || result["cost"] != 0.0 {
+		t.Fatalf("expected the unlimited path from A to cost 0, got %v", result)
+	}
+}
@@ -51,12 +51,12 @@ func Concat(path, path2 []string) []string {
 }
 
 // GetDetailedPath returns a slice of objects corresponding to specified slice of ids. Path should alternate between vertices & edges.
-func GetDetailedPath(path []string, g Graph) []map[string]interface{} {
+func GetDetailedPath(path []string, g GraphReader) []map[string]interface{} {
 	detailedPath := []map[string]interface{}{}
 	for _, componentID := range path {
-		if vertex, ok := g.Vertices[componentID]; ok {
+		if vertex, ok := g.GetVertex(componentID); ok {
 			detailedPath = append(detailedPath, vertex.ToJSON())
-		} else if edge, ok := g.Edges[componentID]; ok {
+		} else if edge, ok := g.GetEdge(componentID); ok {
 			detailedPath = append(detailedPath, edge.ToJSON())
 		}
 	}
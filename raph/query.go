@@ -2,22 +2,55 @@ package raph
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"math"
 )
 
-// Query represents a shortest path query. Option is an optional vertex cost that should be included in the shortest path.
+// Mode defines what a query computes.
+type Mode string
+
+const (
+	// ModeShortest computes the path minimizing the sum of the costs. It is the default mode.
+	ModeShortest Mode = "shortest"
+	// ModeFewestHops computes the path crossing the fewest edges. Ties are broken by the sum of the costs.
+	ModeFewestHops Mode = "fewestHops"
+	// ModeTree computes the shortest path tree rooted in the origin. The destination is ignored.
+	ModeTree Mode = "tree"
+	// ModeIsochrone computes the costs of the vertices reachable from the origin within the budget. The destination is ignored.
+	ModeIsochrone Mode = "isochrone"
+	// ModeDisjoint computes K paths sharing no edge, or no vertex but the origin and the destination, with the minimal total cost.
+	ModeDisjoint Mode = "disjoint"
+)
+
+// Query represents a shortest path query.
 type Query struct {
-	From       string      `json:"from"`
-	To         string      `json:"to"`
-	Constraint *Constraint `json:"constraint"`
-	Minimize   []string    `json:"minimize"`
-	Option     string      `json:"option"`
+	From           string      `json:"from"`
+	To             string      `json:"to"`
+	Constraint     *Constraint `json:"constraint"`
+	Minimize       []string    `json:"minimize"`
+	Option         string      `json:"option"`         // vertex cost that should be included in the path
+	Sequence       string      `json:"sequence"`       // label sequence the crossed edges should match (see CompileSequence)
+	Transfers      []Transfer  `json:"transfers"`      // penalties added between consecutive edges
+	Avoid          []string    `json:"avoid"`          // vertices and edges that cannot be crossed
+	Require        []string    `json:"require"`        // vertices and edges that should be crossed
+	Ordered        bool        `json:"ordered"`        // whether or not Require should be crossed in order
+	MaxHops        int         `json:"maxHops"`        // maximum number of edges, if positive
+	Mode           Mode        `json:"mode"`           // ModeShortest by default
+	Selection      *Constraint `json:"selection"`      // vertices returned by the tree and isochrone modes
+	Budget         float64     `json:"budget"`         // maximum cost of the paths, if positive
+	DepartAt       *float64    `json:"departAt"`       // departure time in hours since Monday 00:00, which makes the query time-dependent
+	MinConnection  *float64    `json:"minConnection"`  // overrides the CostMinConnection of the vertices in time-dependent queries
+	NoWait         bool        `json:"noWait"`         // forbids to wait at closed vertices in time-dependent queries
+	Demand         float64     `json:"demand"`         // minimum remaining CostCapacity of the edges, if positive
+	K              int         `json:"k"`              // number of paths of the disjoint mode, 2 by default
+	VertexDisjoint bool        `json:"vertexDisjoint"` // whether or not disjoint paths should share no vertex
+	Objective      Objective   `json:"objective"`      // ObjectiveSum by default
 }
 
 // NewQuery returns a query instance representing the specified JSON string.
 func NewQuery(queryString string) *Query {
-	query := Query{Constraint: &Constraint{Vertex: &Component{}, Edge: &Component{}}}
+	query := Query{Constraint: &Constraint{Vertex: &VertexConstraint{}, Edge: &Component{}}}
 	err := json.Unmarshal([]byte(queryString), &query)
 	if err != nil {
 		log.Fatalln(err)
@@ -25,33 +58,162 @@ func NewQuery(queryString string) *Query {
 	return &query
 }
 
-// Run executes and returns the query on the specified graph.
-func (q Query) Run(graph Graph) map[string]interface{} {
-	// origin and destination are equal
-	if q.From == q.To {
+// Validate returns an error if the query cannot be run.
+func (q Query) Validate() error {
+	if q.Sequence != "" {
+		if _, err := CompileSequence(q.Sequence); err != nil {
+			return err
+		}
+	}
+	if q.Mode != "" && q.Mode != ModeShortest && q.Mode != ModeFewestHops && q.Mode != ModeTree && q.Mode != ModeIsochrone && q.Mode != ModeDisjoint {
+		return fmt.Errorf("unknown mode %q", q.Mode)
+	}
+	for _, c := range []*Constraint{q.Constraint, q.Selection} {
+		if c != nil && !c.Direction.known() {
+			return fmt.Errorf("unknown direction %q", c.Direction)
+		}
+	}
+	if q.MaxHops < 0 {
+		return fmt.Errorf("maxHops should be positive")
+	}
+	if q.Budget < 0 {
+		return fmt.Errorf("budget should be positive")
+	}
+	if q.Mode == ModeIsochrone && q.Budget == 0 {
+		return fmt.Errorf("isochrone mode requires a budget")
+	}
+	if q.MinConnection != nil && *q.MinConnection < 0 {
+		return fmt.Errorf("minConnection should be positive")
+	}
+	if q.Demand < 0 {
+		return fmt.Errorf("demand should be positive")
+	}
+	if q.K < 0 {
+		return fmt.Errorf("k should be positive")
+	}
+	if !q.Objective.known() {
+		return fmt.Errorf("unknown objective %q", q.Objective)
+	}
+	if !q.Objective.isSum() && (q.Option != "" || len(q.Transfers) > 0 || q.DepartAt != nil || q.Budget > 0 || q.Mode == ModeDisjoint) {
+		return fmt.Errorf("%s objective does not support option, transfers, departAt, budget and disjoint mode", q.Objective)
+	}
+	if q.Mode == ModeDisjoint && (q.needsStates() || q.Option != "") {
+		return fmt.Errorf("disjoint mode does not support sequence, transfers, require, maxHops, option and departAt")
+	}
+	if q.Mode == ModeTree && q.splitsVertices() {
+		return fmt.Errorf("tree mode does not support sequence, transfers, require, maxHops and option")
+	}
+	if !q.Ordered && len(q.Require) > 64 {
+		return fmt.Errorf("cannot require more than 64 unordered vertices and edges")
+	}
+	return nil
+}
+
+// selects returns whether or not the vertex satisfies the selection constraint of the query, if any.
+func (q Query) selects(vertex *Vertex) bool {
+	return vertex != nil && (q.Selection == nil || q.Selection.Vertex == nil || q.Selection.Vertex.Accepts(*vertex))
+}
+
+// withinBudget returns whether or not the cost is within the budget of the query, if any.
+func (q Query) withinBudget(cost float64) bool {
+	return q.Budget <= 0 || cost <= q.Budget
+}
+
+// needsStates returns whether or not the query should be run by StateDijkstra, because its requirements span several edges, depend on time or do not sum costs.
+func (q Query) needsStates() bool {
+	return q.Sequence != "" || len(q.Transfers) > 0 || len(q.Require) > 0 || q.MaxHops > 0 || (q.Mode == ModeFewestHops && q.Option != "") || q.DepartAt != nil || !q.Objective.isSum()
+}
+
+// splitsVertices returns whether or not the query can reach a vertex in several states, in which case the shortest paths to different vertices do not form a tree.
+func (q Query) splitsVertices() bool {
+	return q.Sequence != "" || len(q.Transfers) > 0 || len(q.Require) > 0 || q.MaxHops > 0 || q.Option != ""
+}
+
+// Run executes and returns the query on the specified graph, which can be a Graph or a GraphView.
+func (q Query) Run(graph GraphReader) map[string]interface{} {
+	// query is invalid
+	if err := q.Validate(); err != nil {
+		return map[string]interface{}{"path": []string{}, "cost": -1, "error": err.Error()}
+	}
+
+	// hide avoided vertices and edges, and edges without enough capacity
+	if len(q.Avoid) > 0 {
+		graph = NewAvoidView(graph, q.Avoid...)
+	}
+	q = q.withDemand()
+	turns := hasTurns(graph)
+
+	// turns reach vertices in several states, whose shortest paths do not form a tree
+	if q.Mode == ModeTree && turns {
+		return map[string]interface{}{"path": []string{}, "cost": -1, "error": "tree mode does not support turns"}
+	}
+	if q.Mode == ModeTree || q.Mode == ModeIsochrone {
+		return q.runTree(graph, turns)
+	}
+
+	// compute disjoint paths
+	if q.Mode == ModeDisjoint {
+		return q.runDisjoint(graph, turns)
+	}
+
+	// origin and destination are equal, and no edge is required
+	if q.From == q.To && q.Sequence == "" && len(q.Require) == 0 {
 		return map[string]interface{}{"path": []string{}, "cost": 0}
 	}
 
 	// origin or destination do not exist in the graph
-	_, okFrom := graph.Vertices[q.From]
-	_, okTo := graph.Vertices[q.To]
+	_, okFrom := graph.GetVertex(q.From)
+	_, okTo := graph.GetVertex(q.To)
 	if !okFrom || !okTo {
 		return map[string]interface{}{"path": []string{}, "cost": -1}
 	}
 
 	var path []map[string]interface{}
 	var cost float64
-	dijkstra := NewDijkstra(graph)
 
-	if q.Option == "" {
-		path, cost = dijkstra.ShortestPath(q)
-	} else {
-		path, cost = dijkstra.ShortestPathOption(q)
+	switch {
+	case q.needsStates() || turns:
+		path, cost = NewStateDijkstra(graph).ShortestPath(q)
+	case q.Mode == ModeFewestHops:
+		path, cost = NewDijkstra(graph).FewestHops(q)
+	case q.Option == "":
+		path, cost = NewDijkstra(graph).ShortestPath(q)
+	default:
+		path, cost = NewDijkstra(graph).ShortestPathOption(q)
 	}
 
-	if cost == math.Inf(0) {
+	if cost == math.Inf(0) || !q.withinBudget(cost) {
+		path = []map[string]interface{}{}
 		cost = -1
 	}
 
-	return map[string]interface{}{"path": path, "cost": cost}
+	res := map[string]interface{}{"path": path, "cost": cost}
+	if q.Mode == ModeFewestHops && len(path) > 0 {
+		res["hops"] = len(path) / 2
+	}
+	return res
+}
+
+// runTree executes the query in tree or isochrone mode.
+func (q Query) runTree(graph GraphReader, turns bool) map[string]interface{} {
+	var tree map[string]TreeNode
+	_, okFrom := graph.GetVertex(q.From)
+
+	switch {
+	case !okFrom:
+		tree = map[string]TreeNode{}
+	case q.needsStates() || q.Option != "" || turns:
+		tree = NewStateDijkstra(graph).ShortestPathTree(q)
+	default:
+		tree = NewDijkstra(graph).ShortestPathTree(q)
+	}
+
+	if q.Mode == ModeIsochrone {
+		costs := map[string]float64{}
+		for id, node := range tree {
+			costs[id] = node.Cost
+		}
+		return map[string]interface{}{"vertices": costs}
+	}
+	return map[string]interface{}{"tree": tree}
 }
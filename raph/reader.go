@@ -0,0 +1,106 @@
+package raph
+
+import (
+	"strings"
+)
+
+// GraphReader is the read API used by pathfinders. It is implemented by Graph and GraphView.
+type GraphReader interface {
+	GetVertices() map[string]*Vertex
+	GetVertex(id string) (*Vertex, bool)
+	GetEdge(id string) (*Edge, bool)
+	GetConnections(id, label string) []string
+	GetNeighbors(vertex string, constraint Constraint) map[string]bool
+	GetNeighborsWithCostsAndEdges(vertex string, constraint Constraint, minimize ...string) (map[string]float64, map[string]string)
+}
+
+// arc is an edge crossed to reach a neighbor. Inverse arcs cross their edge backwards.
+type arc struct {
+	edge     *Edge
+	neighbor *Vertex
+	inverse  bool
+}
+
+// cost returns the sum of specified costs of the edge and the neighbor.
+func (a arc) cost(minimize []string) float64 {
+	cost := 0.0
+	for _, c := range minimize {
+		cost += a.edge.Costs[c] + a.neighbor.Costs[c]
+	}
+	return cost
+}
+
+// edgeCost returns the sum of specified costs of the edge.
+func (a arc) edgeCost(minimize []string) float64 {
+	cost := 0.0
+	for _, c := range minimize {
+		cost += a.edge.Costs[c]
+	}
+	return cost
+}
+
+// hasEdgeCost returns whether or not the edge has one of the specified costs.
+func (a arc) hasEdgeCost(minimize []string) bool {
+	for _, c := range minimize {
+		if _, ok := a.edge.Costs[c]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getArcs returns the edges and neighbors reachable from vertex under specified constraint.
+func getArcs(r GraphReader, vertex string, constraint Constraint) []arc {
+	arcs := []arc{}
+
+	for _, t := range constraint.traversals() {
+		// retrieve outgoing edges with label
+		for _, e := range r.GetConnections(vertex, t.label) {
+			edge, ok := r.GetEdge(e)
+
+			// assert that edge satifies constraint, and the constraint specific to its label
+			if !ok || !edge.Satisfies(*constraint.Edge) || (t.edge != nil && !edge.Satisfies(*t.edge)) {
+				continue
+			}
+
+			// retrieve edge ends
+			for _, n := range r.GetConnections(edge.ID, t.label) {
+				neighbor, ok := r.GetVertex(n)
+
+				// assert that vertex satifies constraint
+				if ok && constraint.Vertex.Accepts(*neighbor) {
+					arcs = append(arcs, arc{edge, neighbor, strings.HasPrefix(t.label, "~")})
+				}
+			}
+		}
+	}
+
+	return arcs
+}
+
+// getNeighbors retrieves neighbors of vertex under specified constraints.
+func getNeighbors(r GraphReader, vertex string, constraint Constraint) map[string]bool {
+	neighbors := map[string]bool{}
+	for _, a := range getArcs(r, vertex, constraint) {
+		neighbors[a.neighbor.ID] = true
+	}
+	return neighbors
+}
+
+// getNeighborsWithCostsAndEdges returns reachable vertices with their minimal cost and the crossed edge.
+func getNeighborsWithCostsAndEdges(r GraphReader, vertex string, constraint Constraint, minimize ...string) (map[string]float64, map[string]string) {
+	weights := map[string]float64{}
+	crossedEdges := map[string]string{}
+
+	for _, a := range getArcs(r, vertex, constraint) {
+		// compare potential cost to actual cost
+		potentialCost := a.cost(minimize)
+		cost, ok := weights[a.neighbor.ID]
+		if !ok || potentialCost < cost {
+			weights[a.neighbor.ID] = potentialCost
+			crossedEdges[a.neighbor.ID] = a.edge.ID
+		}
+	}
+
+	return weights, crossedEdges
+}
@@ -0,0 +1,466 @@
+package raph
+
+import (
+	"container/heap"
+	"math"
+	"strconv"
+)
+
+// State is a node of the search space explored by StateDijkstra. A vertex can be reached in several states, e.g. at different positions of a label sequence or through different edges.
+type State struct {
+	Vertex   string
+	Edge     string // edge crossed to reach the vertex, only tracked when needed
+	Pos      int    // state of the label sequence automaton
+	Option   bool   // whether or not the option has been included
+	Required uint64 // required vertices and edges visited: their number if ordered, a bitmask otherwise
+	Hops     int    // number of edges crossed, only tracked when limited
+}
+
+// Key returns the string identifying the state.
+func (s State) Key() string {
+	return s.Vertex + "\x00" + s.Edge + "\x00" + strconv.Itoa(s.Pos) + "\x00" + strconv.FormatBool(s.Option) + "\x00" + strconv.FormatUint(s.Required, 16) + "\x00" + strconv.Itoa(s.Hops)
+}
+
+// transition is a move from a state to another one, crossing an edge. Including the option does not cross any edge.
+type transition struct {
+	state  State
+	edge   string
+	weight float64
+}
+
+// StateDijkstra instance is used to compute Dijkstra algorithm on the product of the graph and the query requirements (label sequence, transfers, option, required vertices and edges) or the turns of the vertices. Its maps are indexed by state keys.
+type StateDijkstra struct {
+	G      GraphReader
+	Costs  map[string]float64
+	Hops   map[string]int
+	States map[string]State
+	PredsS map[string]string
+	PredsE map[string]string
+	done   map[string]bool
+	queue  *stateQueue
+}
+
+// NewStateDijkstra initializes and returns a StateDijkstra instance with graph g.
+func NewStateDijkstra(g GraphReader) *StateDijkstra {
+	return &StateDijkstra{g, map[string]float64{}, map[string]int{}, map[string]State{}, map[string]string{}, map[string]string{}, map[string]bool{}, &stateQueue{}}
+}
+
+// Reset resets the StateDijkstra instance for further use.
+func (d *StateDijkstra) Reset() {
+	*d = *NewStateDijkstra(d.G)
+}
+
+// ShortestPath returns the shortest path satisfying the query with its cost. If no path exists, the cost is +infinity.
+func (d *StateDijkstra) ShortestPath(query Query) ([]map[string]interface{}, float64) {
+	search, err := newStateSearch(query)
+	if err != nil {
+		return []map[string]interface{}{}, math.Inf(0)
+	}
+
+	key := d.run(search, func(state State) bool {
+		return state.Vertex == query.To && search.accepts(state)
+	})
+	if key == "" {
+		return []map[string]interface{}{}, math.Inf(0)
+	}
+
+	path, option := d.GetPath(key)
+	detailedPath := GetDetailedPath(path, d.G)
+	for _, vertex := range detailedPath {
+		if option != "" && vertex["id"] == option {
+			vertex["option"] = query.Option
+		}
+	}
+
+	// annotate vertices with their arrival and wait times, and edges with their departure time
+	if search.timed {
+		arrival, wait := *query.DepartAt, 0.0
+		for i, p := range d.schedule(search, key) {
+			detailedPath[2*i]["arrival"] = arrival
+			detailedPath[2*i]["wait"] = wait + p.departure - p.ready
+			detailedPath[2*i+1]["departure"] = p.departure
+			arrival, wait = p.arrival, p.open-p.arrival
+		}
+		detailedPath[len(detailedPath)-1]["arrival"] = arrival
+		detailedPath[len(detailedPath)-1]["wait"] = wait
+	}
+
+	return detailedPath, query.Objective.value(d.Costs[key])
+}
+
+// GetPath returns the path leading to the state specified by its key, along with the vertex where the option has been included.
+func (d *StateDijkstra) GetPath(key string) ([]string, string) {
+	path := []string{d.States[key].Vertex}
+	option := ""
+
+	for {
+		pred, ok := d.PredsS[key]
+		if !ok {
+			break
+		}
+		if edge := d.PredsE[key]; edge != "" {
+			path = append(path, edge, d.States[pred].Vertex)
+		} else if d.States[key].Option {
+			option = d.States[key].Vertex
+		}
+		key = pred
+	}
+
+	Reverse(path)
+	return path, option
+}
+
+// schedule returns the passages of the edges of the path leading to the state specified by its key, in path order.
+func (d *StateDijkstra) schedule(search *stateSearch, key string) []passage {
+	passages := []passage{}
+
+	for {
+		pred, ok := d.PredsS[key]
+		if !ok {
+			break
+		}
+		if edge := d.PredsE[key]; edge != "" {
+			state := d.States[pred]
+			vertex, _ := d.G.GetVertex(state.Vertex)
+			previous, _ := d.G.GetEdge(state.Edge)
+			e, _ := d.G.GetEdge(edge)
+			neighbor, _ := d.G.GetVertex(d.States[key].Vertex)
+
+			p, _ := search.traverse(vertex, previous, arc{e, neighbor, !e.Tos[neighbor.ID]}, stateItem{pred, d.Costs[pred], d.Hops[pred]})
+			passages = append(passages, p)
+		}
+		key = pred
+	}
+
+	for left, right := 0, len(passages)-1; left < right; left, right = left+1, right-1 {
+		passages[left], passages[right] = passages[right], passages[left]
+	}
+	return passages
+}
+
+// run explores states from the origin of the search in increasing cost order (or hops then cost order, in fewest hops mode), costs being combined according to the objective of the query, until isTarget returns true. It returns the key of the target state, or an empty string if none is reachable.
+func (d *StateDijkstra) run(search *stateSearch, isTarget func(State) bool) string {
+	d.Reset()
+	d.queue.byHops = search.query.Mode == ModeFewestHops
+	d.push(stateItem{search.start().Key(), search.query.Objective.initial(), 0}, search.start(), "", "")
+
+	for d.queue.Len() > 0 {
+		item := heap.Pop(d.queue).(stateItem)
+		if d.done[item.key] {
+			continue
+		}
+		d.done[item.key] = true
+
+		state := d.States[item.key]
+		if isTarget(state) {
+			return item.key
+		}
+
+		for _, t := range search.successors(d.G, state, item) {
+			hops := item.hops
+			if t.edge != "" {
+				hops++
+			}
+			cost := search.query.Objective.combine(item.cost, t.weight)
+			if (search.query.MaxHops > 0 && hops > search.query.MaxHops) || !search.query.withinBudget(cost) || math.IsInf(cost, 1) {
+				continue
+			}
+			d.push(stateItem{t.state.Key(), cost, hops}, t.state, item.key, t.edge)
+		}
+	}
+
+	return ""
+}
+
+// push updates the cost and hops of the state if they are not minimal, and queues it.
+func (d *StateDijkstra) push(item stateItem, state State, pred, edge string) {
+	if d.done[item.key] {
+		return
+	}
+	if cost, ok := d.Costs[item.key]; ok && !d.queue.less(item, stateItem{item.key, cost, d.Hops[item.key]}) {
+		return
+	}
+
+	d.Costs[item.key] = item.cost
+	d.Hops[item.key] = item.hops
+	d.States[item.key] = state
+	if pred != "" {
+		d.PredsS[item.key] = pred
+		d.PredsE[item.key] = edge
+	}
+	heap.Push(d.queue, item)
+}
+
+// stateSearch holds the query requirements translated into state transitions.
+type stateSearch struct {
+	query       Query
+	automaton   *Automaton
+	constraints map[string]*Constraint // constraint restricted to each label of the automaton
+	trackEdges  bool                   // whether or not states are distinguished by their incoming edge
+	required    map[string][]int       // indexes of required vertices and edges
+	trackHops   bool                   // whether or not states are distinguished by their number of hops
+	timed       bool                   // whether or not costs are the time elapsed since the departure
+}
+
+// newStateSearch compiles the query requirements.
+func newStateSearch(query Query) (*stateSearch, error) {
+	search := &stateSearch{query: query}
+	search.trackEdges = len(query.Transfers) > 0
+	search.trackHops = query.MaxHops > 0 && query.Mode != ModeFewestHops
+	search.timed = query.DepartAt != nil
+
+	search.required = map[string][]int{}
+	for i, id := range query.Require {
+		search.required[id] = append(search.required[id], i)
+	}
+
+	if query.Sequence == "" {
+		return search, nil
+	}
+
+	automaton, err := CompileSequence(query.Sequence)
+	if err != nil {
+		return nil, err
+	}
+	search.automaton = automaton
+
+	// labels of the sequence replace the labels of the constraint, label specific edge constraints still apply
+	search.constraints = map[string]*Constraint{}
+	for _, label := range automaton.GetLabels() {
+		constraint := query.Constraint.Copy()
+		constraint.Label = label
+		constraint.Labels = map[string]*Component{}
+		if edge, ok := query.Constraint.Labels[label]; ok {
+			constraint.Labels[label] = edge
+		}
+		search.constraints[label] = constraint
+	}
+
+	return search, nil
+}
+
+// start returns the initial state.
+func (s *stateSearch) start() State {
+	return State{Vertex: s.query.From, Required: s.visit(0, s.query.From)}
+}
+
+// accepts returns whether or not a path can end in the specified state.
+func (s *stateSearch) accepts(state State) bool {
+	if s.automaton != nil && !s.automaton.Accepts(state.Pos) {
+		return false
+	}
+	if s.query.Option != "" && !state.Option {
+		return false
+	}
+	if s.query.Ordered {
+		return state.Required == uint64(len(s.query.Require))
+	}
+	return state.Required == 1<<uint(len(s.query.Require))-1
+}
+
+// visit returns the required vertices and edges visited once the specified vertex or edge is visited.
+func (s *stateSearch) visit(required uint64, id string) uint64 {
+	indexes, ok := s.required[id]
+	if !ok {
+		return required
+	}
+
+	// ordered vertices and edges are visited if the previous ones have been visited
+	if s.query.Ordered {
+		for _, i := range indexes {
+			if uint64(i) == required {
+				required++
+			}
+		}
+		return required
+	}
+
+	for _, i := range indexes {
+		required |= 1 << uint(i)
+	}
+	return required
+}
+
+// successors returns the transitions from the specified state, reached with the cost and hops of item.
+func (s *stateSearch) successors(g GraphReader, state State, item stateItem) []transition {
+	transitions := []transition{}
+
+	vertex, ok := g.GetVertex(state.Vertex)
+	if !ok {
+		return transitions
+	}
+	previous, _ := g.GetEdge(state.Edge)
+
+	// include the option at the current vertex
+	if s.query.Option != "" && !state.Option {
+		if cost, ok := vertex.Costs[s.query.Option]; ok {
+			next := state
+			next.Option = true
+			transitions = append(transitions, transition{next, "", cost})
+		}
+	}
+
+	// cross edges, following the automaton if any
+	if s.automaton == nil {
+		for _, a := range getArcs(g, state.Vertex, *s.query.Constraint) {
+			if canTurn(vertex, previous, a) {
+				transitions = s.appendCross(transitions, state, vertex, previous, a, state.Pos, item)
+			}
+		}
+		return transitions
+	}
+
+	for _, pos := range s.automaton.Next(state.Pos) {
+		constraint := s.constraints[s.automaton.Label(pos)]
+		for _, a := range getArcs(g, state.Vertex, *constraint) {
+			if canTurn(vertex, previous, a) {
+				transitions = s.appendCross(transitions, state, vertex, previous, a, pos, item)
+			}
+		}
+	}
+
+	return transitions
+}
+
+// appendCross appends the transition crossing the arc from the specified state to transitions, unless the arc cannot be crossed at that time. In time-dependent searches, the weight is the time elapsed until the neighbor is entered.
+func (s *stateSearch) appendCross(transitions []transition, state State, vertex *Vertex, previous *Edge, a arc, pos int, item stateItem) []transition {
+	t := s.cross(state, previous, a, pos)
+	if s.timed {
+		p, ok := s.traverse(vertex, previous, a, item)
+		if !ok {
+			return transitions
+		}
+		t.weight = p.open - s.time(item.cost)
+	}
+	return append(transitions, t)
+}
+
+// cross returns the transition crossing the arc from the specified state. Transfer penalties between the previous edge and the crossed edge are added to the weight.
+func (s *stateSearch) cross(state State, previous *Edge, a arc, pos int) transition {
+	next := state
+	next.Vertex = a.neighbor.ID
+	next.Edge = ""
+	next.Pos = pos
+	next.Required = s.visit(s.visit(state.Required, a.edge.ID), a.neighbor.ID)
+	if s.trackHops {
+		next.Hops++
+	}
+	weight := s.query.Objective.weight(a, s.query.Minimize)
+
+	// the crossed edge is needed to apply transfers or turns at the neighbor
+	if s.trackEdges || a.neighbor.Turns != nil {
+		next.Edge = a.edge.ID
+	}
+
+	if previous != nil {
+		for _, transfer := range s.query.Transfers {
+			if transfer.Changes(previous, a.edge) {
+				weight += transfer.Cost(s.query.Minimize)
+			}
+		}
+	}
+
+	return transition{next, a.edge.ID, weight}
+}
+
+// time returns the time at which a state is reached with the specified cost, in time-dependent searches.
+func (s *stateSearch) time(cost float64) float64 {
+	return *s.query.DepartAt + cost
+}
+
+// passage is the crossing of an arc in time-dependent searches.
+type passage struct {
+	ready     float64 // time at which the vertex can be left
+	departure float64 // time at which the vertex is left
+	arrival   float64 // time at which the neighbor is reached
+	open      float64 // time at which the neighbor is entered, once open
+}
+
+// traverse returns the passage crossing the arc, the vertex being entered with the cost and hops of item. ok is false if the arc cannot be crossed.
+// Before leaving a vertex other than the origin, the longest of its time cost and its minimum connection time is spent, then the transfer times. Edges with a timetable wait for their next departure and cannot be crossed backwards, other edges are crossed in their time cost.
+// Arrivals outside the windows of the neighbor wait for its opening, or, if the query does not wait, are avoided by leaving later.
+func (s *stateSearch) traverse(vertex *Vertex, previous *Edge, a arc, item stateItem) (p passage, ok bool) {
+	p.ready = s.time(item.cost)
+	if item.hops > 0 {
+		p.ready += math.Max(vertex.Costs[CostTime], s.minConnection(vertex))
+	}
+	if previous != nil {
+		for _, transfer := range s.query.Transfers {
+			if transfer.Changes(previous, a.edge) {
+				p.ready += transfer.Costs[CostTime]
+			}
+		}
+	}
+
+	duration := a.edge.Costs[CostTime]
+	isOpen := func(arrival float64) bool {
+		return a.neighbor.Opens(arrival) == arrival
+	}
+
+	switch {
+	case len(a.edge.Timetable) == 0 && s.query.NoWait:
+		p.arrival = a.neighbor.Opens(p.ready + duration)
+		p.departure, ok = p.arrival-duration, true
+	case len(a.edge.Timetable) == 0:
+		p.departure, p.arrival, ok = p.ready, p.ready+duration, true
+	case a.inverse:
+		return p, false
+	case s.query.NoWait:
+		p.departure, p.arrival, ok = a.edge.Timetable.nextArriving(p.ready, isOpen)
+	default:
+		p.departure, p.arrival, ok = a.edge.Timetable.Next(p.ready)
+	}
+
+	p.open = a.neighbor.Opens(p.arrival)
+	return p, ok && !math.IsInf(p.open, 0)
+}
+
+// minConnection returns the minimum connection time at the vertex: the one of the query if set, the one of the vertex otherwise.
+func (s *stateSearch) minConnection(vertex *Vertex) float64 {
+	if s.query.MinConnection != nil {
+		return *s.query.MinConnection
+	}
+	return vertex.Costs[CostMinConnection]
+}
+
+// canTurn returns whether or not the arc can be crossed from the vertex reached through the previous edge. If both edges are crossed backwards, the turn is checked in the direction of the edges.
+func canTurn(vertex *Vertex, previous *Edge, a arc) bool {
+	if vertex.Turns == nil || previous == nil {
+		return true
+	}
+	if a.inverse && !previous.Tos[vertex.ID] {
+		return vertex.CanTurn(a.edge.ID, previous.ID)
+	}
+	return vertex.CanTurn(previous.ID, a.edge.ID)
+}
+
+// stateItem is an element of the priority queue.
+type stateItem struct {
+	key  string
+	cost float64
+	hops int
+}
+
+// stateQueue is a priority queue of states ordered by cost, or by hops then cost. It implements heap.Interface.
+type stateQueue struct {
+	items  []stateItem
+	byHops bool
+}
+
+// less returns whether or not item a should be explored before item b.
+func (q stateQueue) less(a, b stateItem) bool {
+	if q.byHops && a.hops != b.hops {
+		return a.hops < b.hops
+	}
+	return a.cost < b.cost
+}
+
+func (q stateQueue) Len() int            { return len(q.items) }
+func (q stateQueue) Less(i, j int) bool  { return q.less(q.items[i], q.items[j]) }
+func (q stateQueue) Swap(i, j int)       { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *stateQueue) Push(x interface{}) { q.items = append(q.items, x.(stateItem)) }
+func (q *stateQueue) Pop() interface{} {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
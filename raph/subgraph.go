@@ -0,0 +1,57 @@
+package raph
+
+// Subgraph returns a new graph containing the vertices and edges satisfying the constraint. If the constraint has labels, only edges with one of them are kept. Multiedges are trimmed to the remaining vertices.
+func (g Graph) Subgraph(constraint Constraint) *Graph {
+	vertices := map[string]bool{}
+	for id, vertex := range g.Vertices {
+		if constraint.Vertex.Accepts(*vertex) {
+			vertices[id] = true
+		}
+	}
+
+	edges := map[string]bool{}
+	for id, edge := range g.Edges {
+		if constraint.allowsEdge(edge) {
+			edges[id] = true
+		}
+	}
+
+	return g.SubgraphByIDs(vertices, edges)
+}
+
+// SubgraphByIDs returns a new graph containing the specified vertices and edges. Multiedges are trimmed to the specified vertices, and edges left without origin or destination are dropped.
+func (g Graph) SubgraphByIDs(vertices, edges map[string]bool) *Graph {
+	graph := NewGraph()
+
+	for id, present := range vertices {
+		if vertex, ok := g.Vertices[id]; ok && present {
+			graph.AddVertex(vertex.Copy())
+		}
+	}
+
+	for id, present := range edges {
+		edge, ok := g.Edges[id]
+		if !ok || !present {
+			continue
+		}
+
+		// trim ends to the vertices of the subgraph
+		trimmed := edge.Copy()
+		for from := range trimmed.Froms {
+			if !graph.hasVertex(from) {
+				delete(trimmed.Froms, from)
+			}
+		}
+		for to := range trimmed.Tos {
+			if !graph.hasVertex(to) {
+				delete(trimmed.Tos, to)
+			}
+		}
+
+		if len(trimmed.Froms) > 0 && len(trimmed.Tos) > 0 {
+			graph.AddEdge(trimmed)
+		}
+	}
+
+	return graph
+}
@@ -0,0 +1,82 @@
+package raph
+
+import (
+	"math"
+)
+
+// Durations in hours, the time unit of time-dependent queries.
+const (
+	Day  = 24.0
+	Week = 7 * Day
+)
+
+// Cost keys used by time-dependent queries.
+const (
+	CostTime          = "time"          // time to cross a vertex, or an edge without timetable
+	CostMinConnection = "minConnection" // minimum time between the arrival at a vertex and the departure from it
+)
+
+// Departure is a scheduled crossing of an edge. Time is the departure time in hours since Monday 00:00 and is repeated every week. Duration is the time to reach the end of the edge.
+type Departure struct {
+	Time     float64 `json:"time"`
+	Duration float64 `json:"duration"`
+}
+
+// Timetable is the list of departures of an edge.
+type Timetable []Departure
+
+// AddDeparture adds a departure to the timetable of the edge.
+func (e *Edge) AddDeparture(time, duration float64) {
+	e.Timetable = append(e.Timetable, Departure{time, duration})
+}
+
+// Next returns the departure and arrival times of the departure leaving at or after time at and arriving first. If the timetable is empty, ok is false.
+func (t Timetable) Next(at float64) (departure, arrival float64, ok bool) {
+	return t.nextArriving(at, func(float64) bool {
+		return true
+	})
+}
+
+// nextArriving returns the departure and arrival times of the departure leaving at or after time at and arriving first, among the ones whose arrival is accepted. As departures are repeated every week, only their first occurrence is considered. If none is accepted, ok is false.
+func (t Timetable) nextArriving(at float64, accepts func(arrival float64) bool) (departure, arrival float64, ok bool) {
+	arrival = math.Inf(0)
+	for _, d := range t {
+		// first occurrence of the departure at or after at
+		next := d.Time + math.Ceil((at-d.Time)/Week)*Week
+		if !accepts(next + d.Duration) {
+			continue
+		}
+		if next+d.Duration < arrival || (next+d.Duration == arrival && next > departure) {
+			departure, arrival, ok = next, next+d.Duration, true
+		}
+	}
+	return departure, arrival, ok
+}
+
+// Copy returns a copy of the timetable. Copying a nil timetable returns nil.
+func (t Timetable) Copy() Timetable {
+	if t == nil {
+		return nil
+	}
+	return append(Timetable{}, t...)
+}
+
+// Merge returns the timetable completed with the departures of the specified timetable, without duplicates.
+func (t Timetable) Merge(timetable Timetable) Timetable {
+	for _, d := range timetable {
+		if !t.contains(d) {
+			t = append(t, d)
+		}
+	}
+	return t
+}
+
+// contains returns whether or not the timetable contains the departure.
+func (t Timetable) contains(departure Departure) bool {
+	for _, d := range t {
+		if d == departure {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package raph
+
+import (
+	"testing"
+)
+
+func TestTimetableNext(t *testing.T) {
+	timetable := Timetable{{Week - 1, 2}, {1, 10}, {9, 5}, {10, 2}}
+
+	for _, c := range []struct {
+		at, departure, arrival float64
+	}{
+		{0.5, 1, 11},                      // first departure of the week
+		{8, 10, 12},                       // a later departure arriving first
+		{12, Week - 1, Week + 1},          // Sunday night departure arriving on Monday
+		{Week - 0.5, Week + 1, Week + 11}, // next week
+	} {
+		departure, arrival, ok := timetable.Next(c.at)
+		if !ok || departure != c.departure || arrival != c.arrival {
+			t.Errorf("expected the departure at %v to arrive at %v from %v, got %v to %v", c.departure, c.arrival, c.at, departure, arrival)
+		}
+	}
+
+	if _, _, ok := (Timetable{}).Next(0); ok {
+		t.Errorf("expected an empty timetable to have no departure")
+	}
+}
+
+func TestTimetableQuery(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"AB", "A", "B", map[string]float64{"time": 1}},
+		testEdge{"BC", "B", "C", map[string]float64{"time": 100}},
+	)
+	g.Edges["BC"].AddDeparture(10, 2)
+	g.Edges["BC"].AddDeparture(Week-1, 1)
+
+	// wait at B for the departure at 10, the cost being the time elapsed
+	result := NewQuery(`{"from": "A", "to": "C", "constraint": {"label": "road"}, "departAt": 8}`).Run(*g)
+	path := result["path"].([]map[string]interface{})
+	if result["cost"] != 4.0 || path[2]["wait"] != 1.0 || path[3]["departure"] != 10.0 || path[4]["arrival"] != 12.0 {
+		t.Fatalf("expected to wait at B for the departure at 10, got %v", result)
+	}
+
+	// the Sunday night departure arrives next week
+	result = NewQuery(`{"from": "A", "to": "C", "constraint": {"label": "road"}, "departAt": 100}`).Run(*g)
+	if result["cost"] != Week-100 {
+		t.Fatalf("expected to arrive at the end of the week, got %v", result)
+	}
+
+	// timetabled edges cannot be crossed backwards
+	result = NewQuery(`{"from": "C", "to": "B", "constraint": {"label": "road", "direction": "in"}, "departAt": 8}`).Run(*g)
+	if result["cost"] != -1.0 {
+		t.Fatalf("expected no backward crossing of the timetable, got %v", result)
+	}
+	result = NewQuery(`{"from": "B", "to": "A", "constraint": {"label": "road", "direction": "in"}, "departAt": 8}`).Run(*g)
+	if result["cost"] != 1.0 {
+		t.Fatalf("expected edges without timetable to be crossed backwards, got %v", result)
+	}
+}
+
+func TestMinConnection(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"AB", "A", "B", map[string]float64{"time": 1}},
+		testEdge{"BC", "B", "C", nil},
+	)
+	g.Edges["BC"].AddDeparture(10, 1)
+	g.Vertices["A"].SetCost(CostMinConnection, 5)
+	g.Vertices["B"].SetCost(CostMinConnection, 2)
+
+	// the minimum connection of A does not apply at the origin
+	query := `{"from": "A", "to": "C", "constraint": {"label": "road"}, "departAt": 8`
+	for _, c := range []struct {
+		override string
+		cost     float64
+	}{
+		{"", Week - 8 + 11}, // ready at B at 11, after the departure at 10
+		{`, "minConnection": 0.5`, 3},
+		{`, "minConnection": 1`, 3}, // ready at B right at the departure
+	} {
+		if result := NewQuery(query + c.override + `}`).Run(*g); result["cost"] != c.cost {
+			t.Errorf("expected the path with %q to cost %v, got %v", c.override, c.cost, result)
+		}
+	}
+
+	if result := NewQuery(query + `, "minConnection": -1}`).Run(*g); result["error"] == nil {
+		t.Errorf("expected a negative minConnection to be rejected, got %v", result)
+	}
+}
@@ -0,0 +1,218 @@
+package raph
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by Transaction.Commit when an operation is invalid.
+var (
+	ErrDuplicateID   = errors.New("duplicate id")
+	ErrUnknownID     = errors.New("unknown id")
+	ErrDanglingEdge  = errors.New("dangling edge")
+	ErrLabelConflict = errors.New("label conflict")
+)
+
+// operation is a pending mutation of a transaction.
+type operation struct {
+	kind   string
+	vertex *Vertex
+	edge   *Edge
+	id     string
+	cost   string
+	value  float64
+}
+
+// Transaction collects additions, removals and cost updates and applies them to the graph at once. Either all of them are applied or none.
+type Transaction struct {
+	g   *Graph
+	ops []operation
+}
+
+// Begin returns a new transaction on the graph.
+func (g *Graph) Begin() *Transaction {
+	return &Transaction{g, []operation{}}
+}
+
+// AddVertex adds a vertex to the graph on commit.
+func (t *Transaction) AddVertex(v *Vertex) {
+	t.ops = append(t.ops, operation{kind: "addVertex", vertex: v, id: v.ID})
+}
+
+// AddEdge adds an edge to the graph on commit.
+func (t *Transaction) AddEdge(e *Edge) {
+	t.ops = append(t.ops, operation{kind: "addEdge", edge: e, id: e.ID})
+}
+
+// RemoveVertex removes a vertex from the graph on commit. Edges connected to the vertex should be removed within the same transaction.
+func (t *Transaction) RemoveVertex(id string) {
+	t.ops = append(t.ops, operation{kind: "removeVertex", id: id})
+}
+
+// RemoveEdge removes an edge from the graph on commit.
+func (t *Transaction) RemoveEdge(id string) {
+	t.ops = append(t.ops, operation{kind: "removeEdge", id: id})
+}
+
+// SetCost sets a cost value of a vertex or an edge on commit.
+func (t *Transaction) SetCost(id, cost string, value float64) {
+	t.ops = append(t.ops, operation{kind: "setCost", id: id, cost: cost, value: value})
+}
+
+// setReserved sets the reserved capacity of an edge on commit.
+func (t *Transaction) setReserved(id string, value float64) {
+	t.ops = append(t.ops, operation{kind: "setReserved", id: id, value: value})
+}
+
+// Rollback discards all pending operations.
+func (t *Transaction) Rollback() {
+	t.ops = []operation{}
+}
+
+// Commit validates pending operations and applies them to the graph. If one of them is invalid, the graph is left untouched, the transaction is rolled back and the error is returned.
+func (t *Transaction) Commit() error {
+	if err := t.validate(); err != nil {
+		t.Rollback()
+		return err
+	}
+
+	for _, op := range t.ops {
+		op.apply(t.g)
+	}
+
+	t.ops = []operation{}
+	return nil
+}
+
+// validate simulates pending operations against the graph and returns the first error encountered.
+func (t *Transaction) validate() error {
+	// ids touched by the transaction, with their presence after the operations applied so far
+	vertices := map[string]bool{}
+	edges := map[string]*Edge{}
+
+	hasVertex := func(id string) bool {
+		if present, ok := vertices[id]; ok {
+			return present
+		}
+		return t.g.hasVertex(id)
+	}
+	getEdge := func(id string) *Edge {
+		if edge, ok := edges[id]; ok {
+			return edge
+		}
+		return t.g.Edges[id]
+	}
+
+	removedVertices := map[string]bool{}
+	for i, op := range t.ops {
+		switch op.kind {
+		case "addVertex":
+			if hasVertex(op.id) || getEdge(op.id) != nil {
+				return fmt.Errorf("operation %d: %w: %s", i, ErrDuplicateID, op.id)
+			}
+			vertices[op.id] = true
+		case "addEdge":
+			if hasVertex(op.id) || getEdge(op.id) != nil {
+				return fmt.Errorf("operation %d: %w: %s", i, ErrDuplicateID, op.id)
+			}
+			if err := checkLabel(op.edge.Label); err != nil {
+				return fmt.Errorf("operation %d: %w", i, err)
+			}
+			// operations are applied in order, so the ends should exist when the edge is added
+			if err := checkEnds(op.edge, hasVertex); err != nil {
+				return fmt.Errorf("operation %d: edge %s: %w", i, op.id, err)
+			}
+			edges[op.id] = op.edge
+		case "removeVertex":
+			if !hasVertex(op.id) {
+				return fmt.Errorf("operation %d: %w: %s", i, ErrUnknownID, op.id)
+			}
+			vertices[op.id] = false
+			removedVertices[op.id] = true
+		case "removeEdge":
+			if getEdge(op.id) == nil {
+				return fmt.Errorf("operation %d: %w: %s", i, ErrUnknownID, op.id)
+			}
+			edges[op.id] = nil
+		case "setCost":
+			if !hasVertex(op.id) && getEdge(op.id) == nil {
+				return fmt.Errorf("operation %d: %w: %s", i, ErrUnknownID, op.id)
+			}
+		case "setReserved":
+			if getEdge(op.id) == nil {
+				return fmt.Errorf("operation %d: %w: %s", i, ErrUnknownID, op.id)
+			}
+		}
+	}
+
+	// edges added by the transaction should still connect existing vertices
+	for id, edge := range edges {
+		if edge != nil {
+			if err := checkEnds(edge, hasVertex); err != nil {
+				return fmt.Errorf("edge %s: %w", id, err)
+			}
+		}
+	}
+
+	// remaining edges of the graph should not be connected to removed vertices, even if they are added back
+	if len(removedVertices) > 0 {
+		isKept := func(id string) bool {
+			return !removedVertices[id]
+		}
+		for id, edge := range t.g.Edges {
+			if _, ok := edges[id]; !ok {
+				if err := checkEnds(edge, isKept); err != nil {
+					return fmt.Errorf("edge %s: %w", id, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply applies the operation to the graph.
+func (op operation) apply(g *Graph) {
+	switch op.kind {
+	case "addVertex":
+		g.AddVertex(op.vertex)
+	case "addEdge":
+		g.AddEdge(op.edge)
+	case "removeVertex":
+		g.RemoveVertex(op.id)
+	case "removeEdge":
+		g.RemoveEdge(op.id)
+	case "setCost":
+		if vertex, ok := g.Vertices[op.id]; ok {
+			vertex.SetCost(op.cost, op.value)
+		} else if edge, ok := g.Edges[op.id]; ok {
+			edge.SetCost(op.cost, op.value)
+		}
+	case "setReserved":
+		g.Edges[op.id].Reserved = op.value
+	}
+}
+
+// checkLabel returns an error if the edge label would conflict with the connections index.
+func checkLabel(label string) error {
+	if strings.HasPrefix(label, "~") || strings.Contains(label, ":") {
+		return fmt.Errorf("%w: %q is reserved", ErrLabelConflict, label)
+	}
+	return nil
+}
+
+// checkEnds returns an error if one of the ends of the edge does not exist.
+func checkEnds(edge *Edge, hasVertex func(string) bool) error {
+	for from := range edge.Froms {
+		if !hasVertex(from) {
+			return fmt.Errorf("%w: unknown vertex %s", ErrDanglingEdge, from)
+		}
+	}
+	for to := range edge.Tos {
+		if !hasVertex(to) {
+			return fmt.Errorf("%w: unknown vertex %s", ErrDanglingEdge, to)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package raph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTransactionEdgeBeforeVertex(t *testing.T) {
+	g := NewGraph()
+	g.AddVertex(NewVertex("A", "city"))
+
+	tx := g.Begin()
+	tx.AddEdge(NewEdge("E", "road", "A", "B"))
+	tx.AddVertex(NewVertex("B", "city"))
+	if err := tx.Commit(); !errors.Is(err, ErrDanglingEdge) {
+		t.Fatalf("expected ErrDanglingEdge, got %v", err)
+	}
+	if len(g.Vertices) != 1 || len(g.Edges) != 0 {
+		t.Fatalf("graph should be untouched, got %d vertices and %d edges", len(g.Vertices), len(g.Edges))
+	}
+
+	tx.AddVertex(NewVertex("B", "city"))
+	tx.AddEdge(NewEdge("E", "road", "A", "B"))
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	res := NewQuery(`{"from": "A", "to": "B", "constraint": {"label": "road"}}`).Run(*g)
+	if res["cost"] != 0.0 || len(res["path"].([]map[string]interface{})) != 3 {
+		t.Fatalf("expected path A E B, got %v", res)
+	}
+}
+
+func TestTransactionEmptyLabel(t *testing.T) {
+	g := NewGraph()
+	tx := g.Begin()
+	tx.AddVertex(NewVertex("A", "city"))
+	tx.AddVertex(NewVertex("B", "city"))
+	tx.AddEdge(NewEdge("E", "", "A", "B"))
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.GetConnections("A", "")) != 1 {
+		t.Fatalf("expected A to be connected to E, got %v", g.Connections)
+	}
+}
+
+func TestTransactionReservedLabel(t *testing.T) {
+	g := NewGraph()
+	tx := g.Begin()
+	tx.AddVertex(NewVertex("A", "city"))
+	tx.AddEdge(NewEdge("E", "~road", "A", "A"))
+	if err := tx.Commit(); !errors.Is(err, ErrLabelConflict) {
+		t.Fatalf("expected ErrLabelConflict, got %v", err)
+	}
+}
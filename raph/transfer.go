@@ -0,0 +1,29 @@
+package raph
+
+// Transfer is a penalty applied between two consecutive edges when their label (On is "label") or their prop On change. Costs are counted like vertex and edge costs: only the keys to minimize are added.
+type Transfer struct {
+	On    string             `json:"on"`
+	Costs map[string]float64 `json:"costs"`
+}
+
+// Changes returns whether or not the transfer applies between edges e1 and e2. A prop changes when the edges do not share any of its values.
+func (t Transfer) Changes(e1, e2 *Edge) bool {
+	if t.On == "label" {
+		return e1.Label != e2.Label
+	}
+
+	values1, values2 := e1.Props[t.On], e2.Props[t.On]
+	if len(values1) == 0 && len(values2) == 0 {
+		return false
+	}
+	return !ContainsOne(values1, values2)
+}
+
+// Cost returns the sum of specified costs of the transfer.
+func (t Transfer) Cost(minimize []string) float64 {
+	cost := 0.0
+	for _, c := range minimize {
+		cost += t.Costs[c]
+	}
+	return cost
+}
@@ -0,0 +1,85 @@
+package raph
+
+import (
+	"math"
+)
+
+// TreeNode is the entry of a vertex in a shortest path tree: the cost of the shortest path from the root, and the vertex and edge preceding the vertex on that path. The root has no predecessor.
+type TreeNode struct {
+	Cost float64 `json:"cost"`
+	Pred string  `json:"pred"`
+	Edge string  `json:"edge"`
+}
+
+// ShortestPathTree returns the shortest path tree rooted in query.From. Only vertices reachable within query.Budget and satisfying query.Selection, if any, are returned. Graphs with turns have no shortest path tree, so the tree is empty.
+func (d *Dijkstra) ShortestPathTree(query Query) map[string]TreeNode {
+	if d.turns {
+		return map[string]TreeNode{}
+	}
+
+	// StateDijkstra stops exploring beyond the budget
+	if query.Budget > 0 {
+		return NewStateDijkstra(d.G).ShortestPathTree(query)
+	}
+
+	d.ComputeCosts(query)
+
+	tree := map[string]TreeNode{}
+	for id, cost := range d.Costs {
+		vertex, _ := d.G.GetVertex(id)
+		if cost != math.Inf(0) && query.withinBudget(cost) && query.selects(vertex) {
+			tree[id] = TreeNode{cost, d.PredsV[id], d.PredsE[id]}
+		}
+	}
+	return tree
+}
+
+// ShortestPathTree returns the shortest path tree rooted in query.From. Only vertices reachable within query.Budget and satisfying query.Selection, if any, are returned. A vertex can be reached in several states: the best state satisfying the query requirements is kept. Its predecessor is the one of that state, so predecessors only chain into a tree if every vertex has a single state, i.e. without sequence, transfers, require, maxHops, option and turns: costs remain exact otherwise.
+func (d *StateDijkstra) ShortestPathTree(query Query) map[string]TreeNode {
+	search, err := newStateSearch(query)
+	if err != nil {
+		return map[string]TreeNode{}
+	}
+
+	tree := map[string]TreeNode{}
+	for id, key := range d.runAll(search) {
+		vertex, _ := d.G.GetVertex(id)
+		if !query.selects(vertex) {
+			continue
+		}
+
+		// skip transitions that do not cross any edge
+		node := TreeNode{Cost: query.Objective.value(d.Costs[key])}
+		for current := key; node.Edge == ""; {
+			pred, ok := d.PredsS[current]
+			if !ok {
+				break
+			}
+			node.Pred, node.Edge = d.States[pred].Vertex, d.PredsE[current]
+			current = pred
+		}
+		if node.Edge == "" {
+			node.Pred = ""
+		}
+		tree[id] = node
+	}
+	return tree
+}
+
+// runAll explores all states and returns, for each reachable vertex, the key of its best state satisfying the search requirements.
+func (d *StateDijkstra) runAll(search *stateSearch) map[string]string {
+	d.run(search, func(state State) bool {
+		return false
+	})
+
+	best := map[string]string{}
+	for key, state := range d.States {
+		if !search.accepts(state) {
+			continue
+		}
+		if current, ok := best[state.Vertex]; !ok || d.queue.less(stateItem{key, d.Costs[key], d.Hops[key]}, stateItem{current, d.Costs[current], d.Hops[current]}) {
+			best[state.Vertex] = key
+		}
+	}
+	return best
+}
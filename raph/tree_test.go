@@ -0,0 +1,65 @@
+package raph
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReachableVerticesBudget(t *testing.T) {
+	g := NewGraph()
+	n := 200000
+	for i := 0; i < n; i++ {
+		g.AddVertex(NewVertex(strconv.Itoa(i), "node"))
+	}
+	for i := 0; i+1 < n; i++ {
+		e := NewEdge("e"+strconv.Itoa(i), "road", strconv.Itoa(i), strconv.Itoa(i+1))
+		e.SetCost("time", 1)
+		g.AddEdge(e)
+	}
+
+	start := time.Now()
+	vertices, err := g.GetReachableVertices("0", *NewConstraint("road"), *NewConstraint(""), 3, "time")
+	if err != nil || len(vertices) != 4 || vertices["3"] != 3 {
+		t.Fatalf("expected vertices 0 to 3, got %v, %v", vertices, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("budgeted search should not explore the whole graph, took %v", elapsed)
+	}
+
+	if _, err := g.GetReachableVertices("0", *NewConstraint("road"), *NewConstraint(""), 0, "time"); err == nil {
+		t.Fatalf("expected a missing budget to be rejected")
+	}
+	if result := NewQuery(`{"from": "0", "mode": "isochrone", "constraint": {"label": "road"}}`).Run(*g); result["error"] == nil {
+		t.Fatalf("expected isochrone mode to require a budget, got %v", result)
+	}
+}
+
+func turnGraph() *Graph {
+	time := map[string]float64{"time": 1}
+	g := newTestGraph(testEdge{"SX", "S", "X", time}, testEdge{"SY", "S", "Y", time}, testEdge{"YX", "Y", "X", time}, testEdge{"XT", "X", "T", time})
+	x, _ := g.GetVertex("X")
+	x.ForbidTurn("SX", "XT")
+	return g
+}
+
+func TestTreeRejectsTurns(t *testing.T) {
+	g := turnGraph()
+
+	result := NewQuery(`{"from": "S", "constraint": {"label": "road"}, "minimize": ["time"], "mode": "tree"}`).Run(*g)
+	if _, ok := result["error"]; !ok {
+		t.Fatalf("expected tree mode to reject turns, got %v", result)
+	}
+
+	result = NewQuery(`{"from": "S", "constraint": {"label": "road"}, "minimize": ["time"], "mode": "isochrone", "budget": 10}`).Run(*g)
+	if vertices := result["vertices"].(map[string]float64); vertices["X"] != 1 || vertices["T"] != 3 {
+		t.Fatalf("expected X at 1 and T at 3 through Y, got %v", vertices)
+	}
+}
+
+func TestTreeRejectsStates(t *testing.T) {
+	result := NewQuery(`{"from": "S", "constraint": {"label": "road"}, "minimize": ["time"], "mode": "tree", "maxHops": 2}`).Run(*turnGraph())
+	if _, ok := result["error"]; !ok {
+		t.Fatalf("expected tree mode to reject maxHops, got %v", result)
+	}
+}
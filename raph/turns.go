@@ -0,0 +1,78 @@
+package raph
+
+// Turns are the connections allowed or forbidden at a vertex: outbound edges are indexed by inbound edge. If a vertex allows some outbound edges for an inbound edge, the other ones are forbidden.
+// Inbound and outbound edges are considered in the direction of the edges, which is the direction of travel unless edges are traversed backwards.
+type Turns struct {
+	Allowed   map[string][]string `json:"allowed,omitempty"`
+	Forbidden map[string][]string `json:"forbidden,omitempty"`
+}
+
+// NewTurns returns empty turns.
+func NewTurns() *Turns {
+	return &Turns{map[string][]string{}, map[string][]string{}}
+}
+
+// Copy returns a copy of the turns. Copying nil turns returns nil.
+func (t *Turns) Copy() *Turns {
+	if t == nil {
+		return nil
+	}
+	turns := NewTurns()
+	turns.Merge(*t)
+	return turns
+}
+
+// Merge adds the allowed and forbidden turns of the specified turns.
+func (t *Turns) Merge(turns Turns) {
+	for in, outs := range turns.Allowed {
+		for _, out := range outs {
+			if !Contains(t.Allowed[in], out) {
+				t.Allowed[in] = append(t.Allowed[in], out)
+			}
+		}
+	}
+	for in, outs := range turns.Forbidden {
+		for _, out := range outs {
+			if !Contains(t.Forbidden[in], out) {
+				t.Forbidden[in] = append(t.Forbidden[in], out)
+			}
+		}
+	}
+}
+
+// AllowTurn allows to leave the vertex through the outbound edges when it is reached through the inbound edge. Outbound edges that are not allowed become forbidden.
+func (v *Vertex) AllowTurn(in string, outs ...string) {
+	if v.Turns == nil {
+		v.Turns = NewTurns()
+	}
+	v.Turns.Allowed[in] = append(v.Turns.Allowed[in], outs...)
+}
+
+// ForbidTurn forbids to leave the vertex through the outbound edges when it is reached through the inbound edge.
+func (v *Vertex) ForbidTurn(in string, outs ...string) {
+	if v.Turns == nil {
+		v.Turns = NewTurns()
+	}
+	v.Turns.Forbidden[in] = append(v.Turns.Forbidden[in], outs...)
+}
+
+// CanTurn returns whether or not the vertex can be left through the outbound edge when it is reached through the inbound edge. Paths can always start from a vertex (empty inbound edge).
+func (v Vertex) CanTurn(in, out string) bool {
+	if v.Turns == nil || in == "" {
+		return true
+	}
+	if outs, ok := v.Turns.Allowed[in]; ok && !Contains(outs, out) {
+		return false
+	}
+	return !Contains(v.Turns.Forbidden[in], out)
+}
+
+// hasTurns returns whether or not one of the vertices of the graph restricts its turns.
+func hasTurns(g GraphReader) bool {
+	for _, vertex := range g.GetVertices() {
+		if vertex.Turns != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -4,16 +4,23 @@ import (
 	"encoding/json"
 )
 
-// Vertex represents a vertex instance.
+// Vertex represents a vertex instance. Turns optionally restrict the connections between edges at the vertex. Windows optionally restrict its opening periods in time-dependent queries.
 type Vertex struct {
 	ID    string `json:"id"`
 	Label string `json:"label"`
 	Component
+	Turns   *Turns  `json:"turns,omitempty"`
+	Windows Windows `json:"windows,omitempty"`
 }
 
 // NewVertex returns a new vertex with specified id and label.
 func NewVertex(id, label string) *Vertex {
-	return &Vertex{id, label, *NewComponent()}
+	return &Vertex{id, label, *NewComponent(), nil, nil}
+}
+
+// Copy returns a copy of the vertex.
+func (v Vertex) Copy() *Vertex {
+	return &Vertex{v.ID, v.Label, *v.Component.Copy(), v.Turns.Copy(), v.Windows.Copy()}
 }
 
 // ToJSON formats the vertex to JSON.
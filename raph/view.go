@@ -0,0 +1,102 @@
+package raph
+
+// GraphView is a read-only view of an underlying graph hiding vertices and edges that do not satisfy its filters. Nothing is copied: filters are applied lazily on lookups, so any pathfinder can run on a view.
+type GraphView struct {
+	G            GraphReader
+	VertexFilter func(*Vertex) bool // nil keeps all vertices
+	EdgeFilter   func(*Edge) bool   // nil keeps all edges
+}
+
+// NewGraphView returns a view of g filtered by the specified predicates.
+func NewGraphView(g GraphReader, vertexFilter func(*Vertex) bool, edgeFilter func(*Edge) bool) *GraphView {
+	return &GraphView{g, vertexFilter, edgeFilter}
+}
+
+// NewConstraintView returns a view of g keeping vertices and edges satisfying the constraint. The label of the constraint is ignored.
+func NewConstraintView(g GraphReader, constraint Constraint) *GraphView {
+	vertexFilter := func(v *Vertex) bool {
+		return constraint.Vertex.Accepts(*v)
+	}
+	edgeFilter := func(e *Edge) bool {
+		return e.Satisfies(*constraint.Edge)
+	}
+	return NewGraphView(g, vertexFilter, edgeFilter)
+}
+
+// NewAvoidView returns a view of g hiding the specified vertices and edges.
+func NewAvoidView(g GraphReader, ids ...string) *GraphView {
+	avoided := map[string]bool{}
+	for _, id := range ids {
+		avoided[id] = true
+	}
+	vertexFilter := func(v *Vertex) bool {
+		return !avoided[v.ID]
+	}
+	edgeFilter := func(e *Edge) bool {
+		return !avoided[e.ID]
+	}
+	return NewGraphView(g, vertexFilter, edgeFilter)
+}
+
+// GetVertices returns visible vertices indexed by id.
+func (v GraphView) GetVertices() map[string]*Vertex {
+	vertices := map[string]*Vertex{}
+	for id, vertex := range v.G.GetVertices() {
+		if v.VertexFilter == nil || v.VertexFilter(vertex) {
+			vertices[id] = vertex
+		}
+	}
+	return vertices
+}
+
+// GetVertex returns the vertex with specified id if it is visible.
+func (v GraphView) GetVertex(id string) (*Vertex, bool) {
+	vertex, ok := v.G.GetVertex(id)
+	if !ok || (v.VertexFilter != nil && !v.VertexFilter(vertex)) {
+		return nil, false
+	}
+	return vertex, true
+}
+
+// GetEdge returns the edge with specified id if it is visible.
+func (v GraphView) GetEdge(id string) (*Edge, bool) {
+	edge, ok := v.G.GetEdge(id)
+	if !ok || (v.EdgeFilter != nil && !v.EdgeFilter(edge)) {
+		return nil, false
+	}
+	return edge, true
+}
+
+// visible returns whether or not the vertex or edge specified by its id is visible.
+func (v GraphView) visible(id string) bool {
+	if _, ok := v.GetVertex(id); ok {
+		return true
+	}
+	_, ok := v.GetEdge(id)
+	return ok
+}
+
+// GetConnections returns visible vertices or edges reachable from specified visible edge or vertex.
+func (v GraphView) GetConnections(id, label string) []string {
+	if !v.visible(id) {
+		return nil
+	}
+
+	connections := []string{}
+	for _, connection := range v.G.GetConnections(id, label) {
+		if v.visible(connection) {
+			connections = append(connections, connection)
+		}
+	}
+	return connections
+}
+
+// GetNeighbors retrieves visible neighbors of vertex under specified constraints.
+func (v GraphView) GetNeighbors(vertex string, constraint Constraint) map[string]bool {
+	return getNeighbors(v, vertex, constraint)
+}
+
+// GetNeighborsWithCostsAndEdges returns visible reachable vertices with their minimal cost and the crossed edge.
+func (v GraphView) GetNeighborsWithCostsAndEdges(vertex string, constraint Constraint, minimize ...string) (map[string]float64, map[string]string) {
+	return getNeighborsWithCostsAndEdges(v, vertex, constraint, minimize...)
+}
@@ -0,0 +1,68 @@
+package raph
+
+import (
+	"math"
+	"time"
+)
+
+// Window is an opening period of a vertex in time-dependent queries. Open and Close are in hours since Monday 00:00 and are repeated every week.
+type Window struct {
+	Open  float64 `json:"open"`
+	Close float64 `json:"close"`
+}
+
+// Windows are the opening periods of a vertex. A vertex without windows is always open.
+type Windows []Window
+
+// AddWindow opens the vertex on the specified weekday, from open to close hours. Close can exceed 24 to open the vertex overnight.
+func (v *Vertex) AddWindow(day time.Weekday, open, close float64) {
+	offset := float64((day+6)%7) * Day
+	v.Windows = append(v.Windows, Window{offset + open, offset + close})
+}
+
+// Opens returns the first time at or after time at when the vertex is open. If the vertex never opens, +infinity is returned.
+func (v Vertex) Opens(at float64) float64 {
+	if len(v.Windows) == 0 {
+		return at
+	}
+
+	opens := math.Inf(0)
+	for _, w := range v.Windows {
+		if w.Open > w.Close {
+			continue
+		}
+
+		// first occurrence of the window closing at or after at
+		shift := math.Ceil((at-w.Close)/Week) * Week
+		opens = math.Min(opens, math.Max(at, w.Open+shift))
+	}
+	return opens
+}
+
+// Copy returns a copy of the windows. Copying nil windows returns nil.
+func (w Windows) Copy() Windows {
+	if w == nil {
+		return nil
+	}
+	return append(Windows{}, w...)
+}
+
+// Merge returns the windows completed with the specified windows, without duplicates.
+func (w Windows) Merge(windows Windows) Windows {
+	for _, window := range windows {
+		if !w.contains(window) {
+			w = append(w, window)
+		}
+	}
+	return w
+}
+
+// contains returns whether or not the windows contain the window.
+func (w Windows) contains(window Window) bool {
+	for _, current := range w {
+		if current == window {
+			return true
+		}
+	}
+	return false
+}
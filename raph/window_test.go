@@ -0,0 +1,66 @@
+package raph
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVertexOpens(t *testing.T) {
+	v := NewVertex("A", "node")
+	if v.Opens(42) != 42 {
+		t.Fatalf("expected a vertex without windows to be always open")
+	}
+
+	v.AddWindow(time.Monday, 9, 17)
+	v.AddWindow(time.Sunday, 22, 30) // overnight, until Monday 6:00
+	for _, c := range []struct {
+		at, opens float64
+	}{
+		{2, 2},         // Sunday night window of the previous week
+		{8, 9},         // Monday morning
+		{12, 12},       // Monday afternoon
+		{18, Week - 2}, // Sunday night
+		{Week + 10, Week + 10},
+	} {
+		if opens := v.Opens(c.at); opens != c.opens {
+			t.Errorf("expected the vertex to open at %v from %v, got %v", c.opens, c.at, opens)
+		}
+	}
+
+	closed := NewVertex("B", "node")
+	closed.Windows = Windows{{10, 9}}
+	if opens := closed.Opens(0); !math.IsInf(opens, 1) {
+		t.Errorf("expected a vertex with empty windows never to open, got %v", opens)
+	}
+}
+
+func TestWindowQuery(t *testing.T) {
+	g := newTestGraph(
+		testEdge{"AB", "A", "B", map[string]float64{"time": 1}},
+		testEdge{"BC", "B", "C", map[string]float64{"time": 1}},
+	)
+	g.Vertices["B"].AddWindow(time.Monday, 9, 17)
+
+	// arrive at 7 and wait for the opening at 9
+	result := NewQuery(`{"from": "A", "to": "C", "constraint": {"label": "road"}, "departAt": 6}`).Run(*g)
+	path := result["path"].([]map[string]interface{})
+	if result["cost"] != 4.0 || path[2]["arrival"] != 7.0 || path[2]["wait"] != 2.0 || path[3]["departure"] != 9.0 {
+		t.Fatalf("expected to wait at B until 9, got %v", result)
+	}
+
+	// leave later to arrive at the opening
+	result = NewQuery(`{"from": "A", "to": "C", "constraint": {"label": "road"}, "departAt": 6, "noWait": true}`).Run(*g)
+	path = result["path"].([]map[string]interface{})
+	if result["cost"] != 4.0 || path[0]["wait"] != 2.0 || path[1]["departure"] != 8.0 || path[2]["arrival"] != 9.0 || path[2]["wait"] != 0.0 {
+		t.Fatalf("expected to leave A at 8, got %v", result)
+	}
+
+	// arrivals of timetabled edges should fall within the windows without waiting
+	g.Edges["AB"].AddDeparture(6, 1)
+	g.Edges["AB"].AddDeparture(9, 1)
+	result = NewQuery(`{"from": "A", "to": "C", "constraint": {"label": "road"}, "departAt": 6, "noWait": true}`).Run(*g)
+	if path = result["path"].([]map[string]interface{}); result["cost"] != 5.0 || path[1]["departure"] != 9.0 {
+		t.Fatalf("expected to take the departure at 9, got %v", result)
+	}
+}